@@ -1,32 +1,107 @@
 package chessEngine
 
 import (
-	"math"
 	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"time"
 )
 
 type ChessTree interface {
 }
 
-var MEMIOZE [10]map[uint64]struct {
-	eval  float32
-	moves []Move
-}
-
 type Node struct {
 	evaluator
-	board    Board
+	board    *Board
 	children []struct {
 		*Node
 		Move
 	}
 	topMoves []Move
-	eval     float32
+	eval     Score
 	depth    int
+	// plies from the root
+	ply  int
+	root bool
+	// reached by a null move, after which another one isn't tried
+	nullMove bool
+	search   *searchContext
+}
+
+// searchContext is shared by all the nodes of one search, counting them and
+// telling them when to give up.
+type searchContext struct {
+	stop     *atomic.Bool
+	deadline time.Time
+	nodes    uint64
+	// the nodes of the quiescence search, counted in nodes as well
+	qnodes uint64
+	// root searches done again outside the aspiration window
+	failLows  int
+	failHighs int
+	// the best move of the previous iteration, searched first at the root
+	pvMove Move
+	// nil when the search has no transposition table
+	tt      *TranspositionTable
+	options SearchOptions
+	// the triangular PV table: pv[ply] holds the best line found from ply
+	// on, pvLength[ply] moves long
+	pv       [MAX_DEPTH + 1][MAX_DEPTH + 1]Move
+	pvLength [MAX_DEPTH + 1]int
+	// quiet moves that caused a cutoff, the last two at every ply
+	killers [MAX_DEPTH + 1][2]Move
+	// how much quiet moves of each piece to each square caused cutoffs
+	history [12][64]int32
+}
+
+// updatePV makes move, followed by the line found after it, the best line
+// from ply.
+func (c *searchContext) updatePV(ply int, move Move) {
+	c.pv[ply][0] = move
+	length := 1
+	if ply+1 <= MAX_DEPTH {
+		length += copy(c.pv[ply][1:], c.pv[ply+1][:c.pvLength[ply+1]])
+	}
+	c.pvLength[ply] = length
 }
 
+func (c *searchContext) pvLine(ply int) []Move {
+	return c.pv[ply][:c.pvLength[ply]]
+}
+
+// probe looks the position up in the transposition table for a node ply
+// plies from the root, with a mate score counted from the root.
+func (c *searchContext) probe(key uint64, ply int) (ttEntry, bool) {
+	if c.tt == nil {
+		return ttEntry{}, false
+	}
+	entry, ok := c.tt.probe(key)
+	entry.score = scoreFromTT(entry.score, ply)
+	return entry, ok
+}
+
+func (c *searchContext) store(key uint64, ply int, depth int, score Score, bound Bound, move Move) {
+	if c.tt != nil {
+		c.tt.store(key, depth, scoreToTT(score, ply), bound, move)
+	}
+}
+
+// visit counts a node and reports whether the search has to stop. The clock
+// is only read every 1024 nodes.
+func (c *searchContext) visit() bool {
+	c.nodes++
+	if c.nodes&1023 == 0 && !c.deadline.IsZero() && time.Now().After(c.deadline) {
+		c.stop.Store(true)
+	}
+	return c.stop.Load()
+}
+
+// NewNode makes the root of a search tree over its own copy of board. All
+// nodes of the tree share that copy, pushing a child's move before searching
+// it and popping it afterwards.
 func NewNode(board Board, depth int) *Node {
-	return &Node{board: board, children: nil, topMoves: nil, depth: depth}
+	return &Node{board: board.Clone(), children: nil, topMoves: nil, depth: depth, root: true,
+		search: &searchContext{stop: new(atomic.Bool)}}
 }
 
 func (n *Node) FindChildren(turn bool) {
@@ -36,87 +111,160 @@ func (n *Node) FindChildren(turn bool) {
 	}
 	moves := n.board.GenerateMoves(turn)
 	for _, move := range moves {
-		chBoard := n.board
-		chBoard.Place(move.piece, move.final)
-		chBoard.Place(-1, move.init)
 		children = append(children, struct {
 			*Node
 			Move
-		}{&Node{board: chBoard, children: nil}, move})
+		}{&Node{board: n.board, children: nil, search: n.search, ply: n.ply + 1}, move})
 	}
 	n.children = children
 }
 
-func (n *Node) EvaluateTree(depth int, turn bool) (float32, []Move) {
-	value, exists := MEMIOZE[depth][n.board.Hash()]
-	if exists {
-		return value.eval, value.moves
+// isDrawn tells whether the search should score the node as a draw without
+// looking at its moves. The root is always searched so a move is returned.
+func (n *Node) isDrawn() bool {
+	return !n.root && n.board.drawReason(1) != NO_TERMINATION
+}
+
+// terminalEval scores a node ply plies from the root where the side to move
+// has no legal moves.
+func (n *Node) terminalEval(turn bool, ply int) Score {
+	if !n.board.InCheck(turn) {
+		return 0
+	}
+	if turn {
+		return -matedIn(ply)
+	}
+	return matedIn(ply)
+}
+
+func (n *Node) EvaluateTree(depth int, turn bool) (Score, []Move) {
+	if n.isDrawn() {
+		return 0, []Move{}
+	}
+	key := n.board.Hash()
+	if entry, ok := n.search.probe(key, n.ply); ok && entry.bound == BOUND_EXACT && int(entry.depth) == depth && !n.root {
+		return entry.score, []Move{unpackMove(entry.move)}
 	}
 	if depth == 0 {
-		return n.Evaluate(&n.board, turn), []Move{}
+		return n.Evaluate(n.board, turn), []Move{}
 	}
 	n.FindChildren(turn)
+	if len(n.children) == 0 {
+		n.eval = n.terminalEval(turn, n.ply)
+		return n.eval, []Move{}
+	}
 	var childEvals []struct {
-		float32
+		Score
 		Move
 	}
 	for _, child := range n.children {
+		n.board.Push(child.Move)
 		eval, _ := child.Node.EvaluateTree(depth-1, !turn)
+		n.board.Pop()
 		childEvals = append(childEvals, struct {
-			float32
+			Score
 			Move
 		}{eval, child.Move})
 	}
 	if turn {
-		var mn float32 = math.MaxFloat32
+		var mn Score = INFINITE_SCORE
 		for _, eval := range childEvals {
-			if mn > eval.float32 {
+			if mn > eval.Score {
 				n.topMoves = []Move{eval.Move}
-				n.eval = eval.float32
-				mn = eval.float32
-			} else if mn == eval.float32 && rand.IntN(2) < 1 {
+				n.eval = eval.Score
+				mn = eval.Score
+			} else if mn == eval.Score && rand.IntN(2) < 1 {
 				n.topMoves = []Move{eval.Move}
-				n.eval = eval.float32
-				mn = eval.float32
+				n.eval = eval.Score
+				mn = eval.Score
 			}
 		}
-		MEMIOZE[depth][n.board.Hash()] = struct {
-			eval  float32
-			moves []Move
-		}{n.eval, n.topMoves}
+		n.search.store(key, n.ply, depth, n.eval, BOUND_EXACT, n.topMoves[0])
 		return n.eval, n.topMoves
 	} else {
-		var mx float32 = -math.MaxFloat32
+		var mx Score = -INFINITE_SCORE
 		for _, eval := range childEvals {
-			if mx < eval.float32 {
+			if mx < eval.Score {
 				n.topMoves = []Move{eval.Move}
-				n.eval = eval.float32
-				mx = eval.float32
-			} else if mx == eval.float32 && rand.IntN(2) < 1 {
+				n.eval = eval.Score
+				mx = eval.Score
+			} else if mx == eval.Score && rand.IntN(2) < 1 {
 				n.topMoves = []Move{eval.Move}
-				n.eval = eval.float32
-				mx = eval.float32
+				n.eval = eval.Score
+				mx = eval.Score
 			}
 		}
-		MEMIOZE[depth][n.board.Hash()] = struct {
-			eval  float32
-			moves []Move
-		}{n.eval, n.topMoves}
+		n.search.store(key, n.ply, depth, n.eval, BOUND_EXACT, n.topMoves[0])
 		return n.eval, n.topMoves
 	}
 }
 
-func (n *Node) EvaluateTreeWithPruning(depth int, turn bool, alpha, beta float32) (float32, []Move) {
+// EvaluateTreeWithPruning is the alpha-beta search, returning the score and
+// the principal variation, the line both sides are expected to play. It is
+// a principal variation search: the first move, the best one if the moves
+// are well ordered, gets the whole window and the others only a null window
+// proving they are no better, searched again in full when they are. Once
+// the search is told to stop it unwinds right away and the result must be
+// thrown away.
+func (n *Node) EvaluateTreeWithPruning(depth int, turn bool, alpha, beta Score) (Score, []Move) {
+	n.search.pvLength[n.ply] = 0
+	if n.search.visit() {
+		return 0, []Move{}
+	}
+	if n.isDrawn() {
+		return 0, []Move{}
+	}
 	if depth == 0 {
-		return n.Evaluate(&n.board, turn), []Move{}
+		if n.search.options.Quiescence {
+			return n.quiescence(turn, alpha, beta, 0), []Move{}
+		}
+		return n.Evaluate(n.board, turn), []Move{}
+	}
+	key := n.board.Hash()
+	var hashMove Move
+	if entry, ok := n.search.probe(key, n.ply); ok {
+		hashMove = unpackMove(entry.move)
+		if !n.root && int(entry.depth) >= depth && entry.cutsOff(alpha, beta) {
+			if hashMove.init == nil {
+				return entry.score, []Move{}
+			}
+			n.search.pv[n.ply][0] = hashMove
+			n.search.pvLength[n.ply] = 1
+			return entry.score, []Move{hashMove}
+		}
+	}
+	inCheck := n.board.InCheck(turn)
+	futile := false
+	if !n.root && !inCheck && isNullWindow(alpha, beta) {
+		staticEval := n.Evaluate(n.board, turn)
+		if eval, ok := n.prune(depth, turn, alpha, beta, staticEval); ok {
+			return eval, []Move{}
+		}
+		futile = n.isFutile(depth, turn, alpha, beta, staticEval)
 	}
 	n.FindChildren(turn)
+	if len(n.children) == 0 {
+		n.eval = n.terminalEval(turn, n.ply)
+		n.search.store(key, n.ply, depth, n.eval, BOUND_EXACT, Move{})
+		return n.eval, []Move{}
+	}
+	if n.root && n.search.pvMove.init != nil {
+		hashMove = n.search.pvMove
+	}
+	n.orderMoves(hashMove)
+	defer n.storeResult(key, depth, alpha, beta)
 	if turn {
-		var mn float32 = math.MaxFloat32
-		for _, child := range n.children {
-			eval, _ := child.Node.EvaluateTreeWithPruning(depth-1, !turn, alpha, beta)
+		var mn Score = INFINITE_SCORE
+		for i, child := range n.children {
+			eval, searched := n.searchChild(i, depth, turn, alpha, beta, inCheck, futile)
+			if n.search.stop.Load() {
+				break
+			}
+			if !searched {
+				continue
+			}
 			if mn > eval {
-				n.topMoves = []Move{child.Move}
+				n.search.updatePV(n.ply, child.Move)
 				n.eval = eval
 				mn = eval
 			}
@@ -124,16 +272,22 @@ func (n *Node) EvaluateTreeWithPruning(depth int, turn bool, alpha, beta float32
 				beta = n.eval
 			}
 			if alpha >= beta {
+				n.search.cutoff(n.board, child.Move, n.ply, depth)
 				break
 			}
 		}
-		return n.eval, n.topMoves
 	} else {
-		var mx float32 = -math.MaxFloat32
-		for _, child := range n.children {
-			eval, _ := child.Node.EvaluateTreeWithPruning(depth-1, !turn, alpha, beta)
+		var mx Score = -INFINITE_SCORE
+		for i, child := range n.children {
+			eval, searched := n.searchChild(i, depth, turn, alpha, beta, inCheck, futile)
+			if n.search.stop.Load() {
+				break
+			}
+			if !searched {
+				continue
+			}
 			if mx < eval {
-				n.topMoves = []Move{child.Move}
+				n.search.updatePV(n.ply, child.Move)
 				n.eval = eval
 				mx = eval
 			}
@@ -141,75 +295,177 @@ func (n *Node) EvaluateTreeWithPruning(depth int, turn bool, alpha, beta float32
 				alpha = n.eval
 			}
 			if alpha >= beta {
+				n.search.cutoff(n.board, child.Move, n.ply, depth)
 				break
 			}
 		}
-		return n.eval, n.topMoves
 	}
+	n.topMoves = slices.Clone(n.search.pvLine(n.ply))
+	return n.eval, n.topMoves
+}
+
+// searchChild searches the ith child, all but the first with a null window
+// at the bound the side to move has to beat, white raising alpha and black
+// lowering beta, and again with the whole window if the move beats it. Late
+// quiet moves are searched less deep first, and fully once they beat the
+// bound. At a futile node quiet moves that don't give check are skipped,
+// which the second result tells.
+func (n *Node) searchChild(i int, depth int, turn bool, alpha, beta Score, inCheck, futile bool) (Score, bool) {
+	child := n.children[i]
+	quiet := !n.board.isTactical(child.Move)
+	n.board.Push(child.Move)
+	defer n.board.Pop()
+	if i == 0 {
+		eval, _ := child.Node.EvaluateTreeWithPruning(depth-1, !turn, alpha, beta)
+		return eval, true
+	}
+	givesCheck := n.board.InCheck(!turn)
+	if futile && quiet && !givesCheck {
+		return 0, false
+	}
+	reduction := 0
+	if n.search.options.LateMoveReductions && quiet && !inCheck && !givesCheck &&
+		depth >= LMR_MIN_DEPTH && i >= LMR_FULL_MOVES {
+		reduction = lmrReduction(depth, i)
+	}
+	eval := n.scout(child.Node, depth-1-reduction, turn, alpha, beta)
+	if reduction > 0 && beats(eval, turn, alpha, beta) && !n.search.stop.Load() {
+		eval = n.scout(child.Node, depth-1, turn, alpha, beta)
+	}
+	if eval <= alpha || eval >= beta || n.search.stop.Load() {
+		return eval, true
+	}
+	eval, _ = child.Node.EvaluateTreeWithPruning(depth-1, !turn, alpha, beta)
+	return eval, true
+}
+
+// scout searches child with a null window at the bound the side to move
+// has to beat.
+func (n *Node) scout(child *Node, depth int, turn bool, alpha, beta Score) Score {
+	if turn {
+		eval, _ := child.EvaluateTreeWithPruning(depth, !turn, beta-1, beta)
+		return eval
+	}
+	eval, _ := child.EvaluateTreeWithPruning(depth, !turn, alpha, alpha+1)
+	return eval
+}
+
+// beats tells whether a scout search's result is better for the side to
+// move than the bound it has to beat.
+func beats(eval Score, turn bool, alpha, beta Score) bool {
+	if turn {
+		return eval < beta
+	}
+	return eval > alpha
+}
+
+// storeResult records the outcome of searching the node within alpha and
+// beta, unless the search was stopped before it was done.
+func (n *Node) storeResult(key uint64, depth int, alpha, beta Score) {
+	if n.search.stop.Load() || len(n.topMoves) == 0 {
+		return
+	}
+	bound := BOUND_EXACT
+	if n.eval <= alpha {
+		bound = BOUND_UPPER
+	} else if n.eval >= beta {
+		bound = BOUND_LOWER
+	}
+	n.search.store(key, n.ply, depth, n.eval, bound, n.topMoves[0])
+}
+
+// cutsOff tells whether the entry settles the score of a position searched
+// within alpha and beta, as a score outside the window is as good as exact.
+func (e ttEntry) cutsOff(alpha, beta Score) bool {
+	switch e.bound {
+	case BOUND_EXACT:
+		return true
+	case BOUND_LOWER:
+		return e.score >= beta
+	case BOUND_UPPER:
+		return e.score <= alpha
+	}
+	return false
 }
 
 func (n *Node) EvaluateTreeConcurrent(depth int, turn bool, response chan struct {
-	float32
+	Score
 	Move
 }) {
+	if n.isDrawn() {
+		response <- struct {
+			Score
+			Move
+		}{0, Move{}}
+		return
+	}
 	if depth == 0 {
 		response <- struct {
-			float32
+			Score
 			Move
-		}{n.Evaluate(&n.board, turn), Move{}}
+		}{n.Evaluate(n.board, turn), Move{}}
 		return
 	}
 	n.FindChildren(turn)
+	if len(n.children) == 0 {
+		response <- struct {
+			Score
+			Move
+		}{n.terminalEval(turn, n.ply), Move{}}
+		return
+	}
 	// var wg sync.WaitGroup
 	var childEvals []chan struct {
-		float32
+		Score
 		Move
 	}
 	var childMoves []Move
 	for _, child := range n.children {
 		childEvals = append(childEvals, make(chan struct {
-			float32
+			Score
 			Move
 		}))
 		childMoves = append(childMoves, child.Move)
+		child.Node.board = n.board.Clone()
+		child.Node.board.Push(child.Move)
 		go child.Node.EvaluateTreeConcurrent(depth-1, !turn, childEvals[len(childEvals)-1])
 	}
 	if turn {
-		var mn float32 = math.MaxFloat32
+		var mn Score = INFINITE_SCORE
 		for idx, eval := range childEvals {
 			ev := <-eval
 			move := childMoves[idx]
-			if mn > ev.float32 {
+			if mn > ev.Score {
 				n.topMoves = []Move{move}
-				n.eval = ev.float32
-				mn = ev.float32
-			} else if mn == ev.float32 && rand.IntN(2) < 1 {
+				n.eval = ev.Score
+				mn = ev.Score
+			} else if mn == ev.Score && rand.IntN(2) < 1 {
 				n.topMoves = []Move{move}
-				n.eval = ev.float32
-				mn = ev.float32
+				n.eval = ev.Score
+				mn = ev.Score
 			}
 		}
 		response <- struct {
-			float32
+			Score
 			Move
 		}{mn, n.topMoves[0]}
 	} else {
-		var mx float32 = -math.MaxFloat32
+		var mx Score = -INFINITE_SCORE
 		for idx, eval := range childEvals {
 			ev := <-eval
 			move := childMoves[idx]
-			if mx < ev.float32 {
+			if mx < ev.Score {
 				n.topMoves = []Move{move}
-				n.eval = ev.float32
-				mx = ev.float32
-			} else if mx == ev.float32 && rand.IntN(2) < 1 {
+				n.eval = ev.Score
+				mx = ev.Score
+			} else if mx == ev.Score && rand.IntN(2) < 1 {
 				n.topMoves = []Move{move}
-				n.eval = ev.float32
-				mx = ev.float32
+				n.eval = ev.Score
+				mx = ev.Score
 			}
 		}
 		response <- struct {
-			float32
+			Score
 			Move
 		}{mx, n.topMoves[0]}
 	}
@@ -0,0 +1,166 @@
+package chessEngine
+
+import "math/bits"
+
+// Bitboard is a set of squares, bit rank*8+file standing for the square on
+// that rank and file.
+type Bitboard uint64
+
+func (bb Bitboard) Has(sq int) bool {
+	return bb&(1<<sq) != 0
+}
+
+func (bb Bitboard) Count() int {
+	return bits.OnesCount64(uint64(bb))
+}
+
+// LSB returns the lowest square in the set, which must not be empty.
+func (bb Bitboard) LSB() int {
+	return bits.TrailingZeros64(uint64(bb))
+}
+
+// PopLSB removes the lowest square from the set and returns it.
+func (bb *Bitboard) PopLSB() int {
+	sq := bits.TrailingZeros64(uint64(*bb))
+	*bb &= *bb - 1
+	return sq
+}
+
+func squareBit(sq int) Bitboard {
+	return 1 << sq
+}
+
+func squareIndex(pos Position) int {
+	return int(pos.GetRank())*8 + int(pos.GetFile())
+}
+
+// positionOf returns a shared Position for sq so that hot paths like move
+// generation don't allocate one per square. It must not be modified.
+func positionOf(sq int) Position {
+	return &squarePositions[sq]
+}
+
+func sideIndex(side bool) int {
+	if side {
+		return 1
+	}
+	return 0
+}
+
+// LIGHT_SQUARES holds h1, g2, a8 and every other square of their color.
+const LIGHT_SQUARES Bitboard = 0x55AA55AA55AA55AA
+
+const (
+	NORTH = iota
+	NORTH_EAST
+	EAST
+	NORTH_WEST
+	SOUTH
+	SOUTH_WEST
+	WEST
+	SOUTH_EAST
+)
+
+var directionSteps = [8][2]int{{1, 0}, {1, 1}, {0, 1}, {1, -1}, {-1, 0}, {-1, -1}, {0, -1}, {-1, 1}}
+
+var (
+	squarePositions [64]Pos
+	knightAttacks   [64]Bitboard
+	kingAttacks     [64]Bitboard
+	// indexed by the color of the attacking pawn
+	pawnAttacks [2][64]Bitboard
+	// every square from sq towards the edge in a direction, sq excluded
+	rays [8][64]Bitboard
+)
+
+func init() {
+	for sq := 0; sq < 64; sq++ {
+		rank, file := sq/8, sq%8
+		squarePositions[sq] = Pos{int16(rank), int16(file)}
+
+		knightAttacks[sq] = offsetsFrom(rank, file, [][2]int{{1, 2}, {1, -2}, {2, 1}, {2, -1}, {-1, 2}, {-1, -2}, {-2, 1}, {-2, -1}})
+		kingAttacks[sq] = offsetsFrom(rank, file, [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}})
+		pawnAttacks[0][sq] = offsetsFrom(rank, file, [][2]int{{1, -1}, {1, 1}})
+		pawnAttacks[1][sq] = offsetsFrom(rank, file, [][2]int{{-1, -1}, {-1, 1}})
+
+		for dir, step := range directionSteps {
+			for r, f := rank+step[0], file+step[1]; r >= 0 && r < 8 && f >= 0 && f < 8; r, f = r+step[0], f+step[1] {
+				rays[dir][sq] |= squareBit(r*8 + f)
+			}
+		}
+	}
+}
+
+func offsetsFrom(rank, file int, offsets [][2]int) Bitboard {
+	var bb Bitboard
+	for _, offset := range offsets {
+		r, f := rank+offset[0], file+offset[1]
+		if r >= 0 && r < 8 && f >= 0 && f < 8 {
+			bb |= squareBit(r*8 + f)
+		}
+	}
+	return bb
+}
+
+// rayAttacks returns the squares a slider on sq reaches in direction dir,
+// stopping at and including the first occupied square.
+func rayAttacks(sq int, occupied Bitboard, dir int) Bitboard {
+	attacks := rays[dir][sq]
+	blockers := attacks & occupied
+	if blockers == 0 {
+		return attacks
+	}
+	var blocker int
+	if dir < SOUTH {
+		blocker = blockers.LSB()
+	} else {
+		blocker = 63 - bits.LeadingZeros64(uint64(blockers))
+	}
+	return attacks &^ rays[dir][blocker]
+}
+
+// slowRookAttacks walks the rays one by one. It is the reference the magic
+// tables are built from and checked against, use rookAttacks instead.
+func slowRookAttacks(sq int, occupied Bitboard) Bitboard {
+	return rayAttacks(sq, occupied, NORTH) | rayAttacks(sq, occupied, EAST) |
+		rayAttacks(sq, occupied, SOUTH) | rayAttacks(sq, occupied, WEST)
+}
+
+// slowBishopAttacks is the ray walking reference for bishopAttacks.
+func slowBishopAttacks(sq int, occupied Bitboard) Bitboard {
+	return rayAttacks(sq, occupied, NORTH_EAST) | rayAttacks(sq, occupied, NORTH_WEST) |
+		rayAttacks(sq, occupied, SOUTH_EAST) | rayAttacks(sq, occupied, SOUTH_WEST)
+}
+
+func (b *Board) all() Bitboard {
+	return b.occupied[0] | b.occupied[1]
+}
+
+// attackersOf returns the pieces of side that attack sq.
+func (b *Board) attackersOf(sq int, side bool) Bitboard {
+	base := WHITE_KING
+	if side {
+		base = BLACK_KING
+	}
+	occupied := b.all()
+	queens := b.pieces[base+WHITE_QUEEN]
+	return kingAttacks[sq]&b.pieces[base+WHITE_KING] |
+		knightAttacks[sq]&b.pieces[base+WHITE_KNIGHT] |
+		pawnAttacks[sideIndex(!side)][sq]&b.pieces[base+WHITE_PAWN] |
+		rookAttacks(sq, occupied)&(b.pieces[base+WHITE_ROOK]|queens) |
+		bishopAttacks(sq, occupied)&(b.pieces[base+WHITE_BISHOP]|queens)
+}
+
+// syncBitboards rebuilds the bitboards from squares after it was filled in
+// directly rather than through Place.
+func (b *Board) syncBitboards() {
+	b.pieces = [12]Bitboard{}
+	b.occupied = [2]Bitboard{}
+	for sq := 0; sq < 64; sq++ {
+		piece := b.squares[sq/8][sq%8]
+		if piece != -1 {
+			b.pieces[piece] |= squareBit(sq)
+			b.occupied[sideIndex(piece.Color())] |= squareBit(sq)
+		}
+	}
+}
@@ -3,24 +3,49 @@ package chessEngine
 import (
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"slices"
 
 	"github.com/kishanshukla-2307/chess-engine/utils"
 )
 
 type Board struct {
 	squares [8][8]Piece
-	inCheck bool
-	turn    bool
+	// the same position as squares, one set per piece and one per color
+	pieces   [12]Bitboard
+	occupied [2]Bitboard
+	inCheck  bool
+	turn     bool
+	castling CastlingRights
+	// the file each castling rook starts on, kingside then queenside for
+	// each side, which in Chess960 can be any file on either side of the king
+	rookFiles [2][2]int16
+	chess960  bool
+	enPassant Position
+	halfMoves int
+	fullMoves int
+	// one record per move played, for Pop and repetition detection
+	undos []undo
+	// the Zobrist hash of the position, kept up to date move by move
+	hash uint64
 }
 
+type CastlingRights uint8
+
+const (
+	WHITE_KINGSIDE  CastlingRights = 1
+	WHITE_QUEENSIDE CastlingRights = 2
+	BLACK_KINGSIDE  CastlingRights = 4
+	BLACK_QUEENSIDE CastlingRights = 8
+	ALL_CASTLING    CastlingRights = 15
+)
+
+// GetPiecePositions returns the squares piece stands on, as copies that
+// callers are free to change.
 func (b *Board) GetPiecePositions(piece Piece) []Position {
 	var res []Position
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			if piece == b.squares[i][j] {
-				res = append(res, &Pos{(int16)(i), (int16)(j)})
-			}
-		}
+	for bb := b.pieces[piece]; bb != 0; {
+		res = append(res, copyPosition(positionOf(bb.PopLSB())))
 	}
 	return res
 }
@@ -30,7 +55,18 @@ func (b *Board) Get(pos Position) Piece {
 }
 
 func (b *Board) Place(piece Piece, pos Position) {
+	bit := squareBit(squareIndex(pos))
+	old := b.squares[pos.GetRank()][pos.GetFile()]
+	if old != -1 {
+		b.pieces[old] &^= bit
+		b.occupied[sideIndex(old.Color())] &^= bit
+	}
 	b.squares[pos.GetRank()][pos.GetFile()] = piece
+	if piece != -1 {
+		b.pieces[piece] |= bit
+		b.occupied[sideIndex(piece.Color())] |= bit
+	}
+	b.hash ^= pieceKey(old, pos) ^ pieceKey(piece, pos)
 }
 
 func (b *Board) IsEmpty(pos Position) bool {
@@ -42,72 +78,109 @@ func (b *Board) IsEmpty(pos Position) bool {
 // 	b.Place(-1, init)
 // }
 
+// InitializeBoard sets up the starting position, for chess960 one of the
+// 960 picked at random.
 func (b *Board) InitializeBoard(chess960 bool) error {
 	if chess960 {
-		return errors.New("chess 960 not supported yet")
+		return b.InitializeChess960(rand.IntN(CHESS960_POSITIONS))
 	}
+	b.setUp([8]Piece{WHITE_ROOK, WHITE_KNIGHT, WHITE_BISHOP, WHITE_QUEEN, WHITE_KING, WHITE_BISHOP, WHITE_KNIGHT, WHITE_ROOK})
+	b.chess960 = false
+	return nil
+}
+
+// setUp places the white pieces on the first rank as given, the black ones
+// mirroring them and the pawns, both sides being free to castle with the
+// rooks on either side of their king.
+func (b *Board) setUp(backRank [8]Piece) {
 	for i := 0; i < 8; i++ {
 		for j := 0; j < 8; j++ {
 			b.squares[i][j] = -1
 		}
 	}
-	b.squares[0] = [8]Piece{WHITE_ROOK, WHITE_KNIGHT, WHITE_BISHOP, WHITE_QUEEN, WHITE_KING, WHITE_BISHOP, WHITE_KNIGHT, WHITE_ROOK}
-	b.squares[7] = [8]Piece{BLACK_ROOK, BLACK_KNIGHT, BLACK_BISHOP, BLACK_QUEEN, BLACK_KING, BLACK_BISHOP, BLACK_KNIGHT, BLACK_ROOK}
+	var rooks []int16
+	for i, piece := range backRank {
+		b.squares[0][i] = piece
+		b.squares[7][i] = piece + BLACK_KING
+		if piece == WHITE_ROOK {
+			rooks = append(rooks, int16(i))
+		}
+	}
+	b.rookFiles = [2][2]int16{{rooks[1], rooks[0]}, {rooks[1], rooks[0]}}
 	for i := 0; i < 8; i++ {
 		b.squares[1][i] = WHITE_PAWN
 	}
 	for i := 0; i < 8; i++ {
 		b.squares[6][i] = BLACK_PAWN
 	}
-	return nil
+	b.syncBitboards()
+	b.inCheck = false
+	b.turn = false
+	b.castling = ALL_CASTLING
+	b.enPassant = nil
+	b.halfMoves = 0
+	b.fullMoves = 1
+	b.undos = nil
+	b.hash = b.computeHash()
 }
 
+// Hash returns the Zobrist hash of the position, the same for the same
+// pieces, side to move, castling rights and en passant file however the
+// position was reached.
 func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+// computeHash works the hash out from scratch, for a position set up
+// without Place, Push and Pop.
+func (b *Board) computeHash() uint64 {
 	var hash uint64
-	var i, j int16
-	for i = 0; i < 8; i++ {
-		for j = 0; j < 8; j++ {
-			if !b.IsEmpty(&Pos{i, j}) {
-				hash = hash ^ utils.ZORBIST_TABLE[i*8+j][b.Get(&Pos{i, j})]
-			}
-		}
+	for bb := b.all(); bb != 0; {
+		sq := bb.PopLSB()
+		hash = hash ^ utils.ZORBIST_TABLE[sq][b.squares[sq/8][sq%8]]
 	}
 	if b.turn {
 		hash = hash ^ utils.BLACK_TO__MOVE
 	}
-	return hash
+	return hash ^ b.stateKey()
+}
+
+// stateKey is the part of the hash for the castling rights and the en
+// passant file.
+func (b *Board) stateKey() uint64 {
+	key := utils.CASTLING_RIGHTS[b.castling]
+	if b.enPassant != nil {
+		key ^= utils.EN_PASSANT_FILE[b.enPassant.GetFile()]
+	}
+	return key
+}
+
+func pieceKey(piece Piece, pos Position) uint64 {
+	if piece == -1 {
+		return 0
+	}
+	return utils.ZORBIST_TABLE[squareIndex(pos)][piece]
 }
 
 func (b *Board) IsLegal(p Piece, init Position, final Position) (bool, error) {
 	if b.Get(init) != p {
 		return false, errors.New("No such piece there")
 	}
-	if init.Equal(final) {
-		return false, errors.New("init and final are same")
-	}
-
-	// check if final pos have same color piece
-	if b.Get(final) != -1 {
-		if p.Color() == b.Get(final).Color() {
-			return false, errors.New("attacking same color")
+	move := Move{p, init, final, -1, false}
+	if castle, ok := b.castlingMove(p, init, final); ok {
+		if err := b.canCastle(castle); err != nil {
+			return false, err
 		}
-	}
-
-	if p.IsKing() {
+		move = castle
+	} else if init.Equal(final) {
+		return false, errors.New("init and final are same")
+	} else if b.Get(final) != -1 && p.Color() == b.Get(final).Color() {
+		// check if final pos have same color piece
+		return false, errors.New("attacking same color")
+	} else if p.IsKing() {
 		if !KingAttack(b, p, init, final) {
 			return false, errors.New("King cant move like that")
 		}
-		tarPiece := b.Get(final)
-		b.Place(p, final)
-		b.Place(-1, init)
-		if b.IsAttackedBySide(final, !p.Color()) {
-			b.Place(tarPiece, final)
-			b.Place(p, init)
-			return false, errors.New("King can't move into check")
-		}
-		b.Place(tarPiece, final)
-		b.Place(p, init)
-
 	} else if p.IsPawn() {
 		if !PawnMove(b, p, init, final) {
 			return false, errors.New("Pawn can't move there")
@@ -118,46 +191,315 @@ func (b *Board) IsLegal(p Piece, init Position, final Position) (bool, error) {
 		}
 	}
 
-	// if b.inCheck {
-	tarPiece := b.Get(final)
-	b.Place(p, final)
-	b.Place(-1, init)
-	var kingPos Position
-	if p.Color() {
-		kingPos = b.GetPiecePositions(BLACK_KING)[0]
-	} else {
-		kingPos = b.GetPiecePositions(WHITE_KING)[0]
+	b.Push(move)
+	inCheck := b.InCheck(p.Color())
+	b.Pop()
+	if inCheck && p.IsKing() {
+		return false, errors.New("King can't move into check")
 	}
-	if b.IsAttackedBySide(kingPos, !p.Color()) {
-		b.Place(tarPiece, final)
-		b.Place(p, init)
+	if inCheck {
 		return false, errors.New("in check!")
 	}
-	b.Place(tarPiece, final)
-	b.Place(p, init)
-	// }
 	return true, nil
 }
 
+// canCastle checks everything about a castling move except the king's
+// destination square being attacked, which IsLegal checks for every move.
+// In Chess960 the king and rook may start anywhere on the home rank, every
+// square either of them crosses or lands on must be empty but for the two
+// of them.
+func (b *Board) canCastle(move Move) error {
+	p, init, final := move.piece, move.init, move.final
+	var homeRank int16
+	if p.Color() {
+		homeRank = 7
+	}
+	if init.GetRank() != homeRank || final.GetRank() != homeRank {
+		return errors.New("King can only castle on its home rank")
+	}
+	kingside := final.GetFile() == 6
+	if b.castling&castlingRight(p.Color(), kingside) == 0 {
+		return errors.New("no castling rights on that side")
+	}
+	rookFrom, rookTo := b.castlingRookSquares(move)
+	if b.Get(rookFrom) != p+(WHITE_ROOK-WHITE_KING) {
+		return errors.New("no rook to castle with")
+	}
+	for _, path := range [][2]int16{{init.GetFile(), final.GetFile()}, {rookFrom.GetFile(), rookTo.GetFile()}} {
+		from, to := min(path[0], path[1]), max(path[0], path[1])
+		for file := from; file <= to; file++ {
+			if file != init.GetFile() && file != rookFrom.GetFile() && !b.IsEmpty(&Pos{homeRank, file}) {
+				return errors.New("castling path is blocked")
+			}
+		}
+	}
+	if b.IsAttackedBySide(init, !p.Color()) {
+		return errors.New("can't castle out of check")
+	}
+	step := int16(1)
+	if final.GetFile() < init.GetFile() {
+		step = -1
+	}
+	for file := init.GetFile(); file != final.GetFile(); file += step {
+		if b.IsAttackedBySide(&Pos{homeRank, file + step}, !p.Color()) {
+			return errors.New("can't castle through check")
+		}
+	}
+	return nil
+}
+
+// applyMove moves the pieces for an already validated move, relocating the
+// rook when castling, removing the pawn taken en passant and placing the
+// promoted piece, and keeps the castling rights, en passant square and move
+// clocks up to date. It does not switch the side to move, Push does.
+func (b *Board) applyMove(move Move) {
+	p, init, final, promotion := move.piece, move.init, move.final, move.promotion
+	if p.IsPawn() || (!b.IsEmpty(final) && !move.castling) {
+		b.halfMoves = 0
+	} else {
+		b.halfMoves++
+	}
+	if p.Color() {
+		b.fullMoves++
+	}
+	// the castling rights and en passant file are hashed again at the end
+	b.hash ^= b.stateKey()
+	if p.IsPawn() && b.enPassant != nil && final.Equal(b.enPassant) {
+		b.Place(-1, &Pos{init.GetRank(), final.GetFile()})
+	}
+	b.enPassant = nil
+	if p.IsPawn() && init.GetRank() == pawnStartRank(p.Color()) && utils.Abs(final.Sub(init).GetRank()) == 2 {
+		b.enPassant = positionOf((squareIndex(init) + squareIndex(final)) / 2)
+	}
+	if move.castling {
+		// the king and rook may land on each other's square in Chess960,
+		// so both leave the board before either is placed
+		rookFrom, rookTo := b.castlingRookSquares(move)
+		rook := b.Get(rookFrom)
+		b.Place(-1, rookFrom)
+		b.Place(-1, init)
+		b.Place(p, final)
+		b.Place(rook, rookTo)
+	} else {
+		b.Place(p, final)
+		b.Place(-1, init)
+	}
+	if p.IsPawn() && promotion != -1 {
+		b.Place(promotion, final)
+	}
+	if p == WHITE_KING {
+		b.castling &^= WHITE_KINGSIDE | WHITE_QUEENSIDE
+	} else if p == BLACK_KING {
+		b.castling &^= BLACK_KINGSIDE | BLACK_QUEENSIDE
+	}
+	b.castling &^= b.castlingRightsLostAt(init) | b.castlingRightsLostAt(final)
+	b.hash ^= b.stateKey()
+}
+
+// undo holds what Pop needs to take a move back.
+type undo struct {
+	move      Move
+	captured  Piece
+	castling  CastlingRights
+	enPassant Position
+	halfMoves int
+	fullMoves int
+	inCheck   bool
+	hash      uint64
+}
+
+// Push plays move without checking that it is legal, recording everything
+// needed to take it back with Pop.
+func (b *Board) Push(move Move) {
+	captured := b.Get(move.final)
+	if move.castling {
+		captured = -1
+	} else if move.piece.IsPawn() && b.enPassant != nil && move.final.Equal(b.enPassant) {
+		captured = b.Get(&Pos{move.init.GetRank(), move.final.GetFile()})
+	}
+	b.undos = append(b.undos, undo{
+		move:      move,
+		captured:  captured,
+		castling:  b.castling,
+		enPassant: b.enPassant,
+		halfMoves: b.halfMoves,
+		fullMoves: b.fullMoves,
+		inCheck:   b.inCheck,
+		hash:      b.hash,
+	})
+	b.applyMove(move)
+	b.turn = !b.turn
+	b.hash ^= utils.BLACK_TO__MOVE
+}
+
+// Pop takes back the last move played with Push or MakeMove and returns it.
+func (b *Board) Pop() (Move, error) {
+	if len(b.undos) == 0 {
+		return Move{}, errors.New("no move to take back")
+	}
+	u := b.undos[len(b.undos)-1]
+	b.undos = b.undos[:len(b.undos)-1]
+	move := u.move
+
+	if move.castling {
+		rookFrom, rookTo := b.castlingRookSquares(move)
+		rook := b.Get(rookTo)
+		b.Place(-1, rookTo)
+		b.Place(-1, move.final)
+		b.Place(rook, rookFrom)
+		b.Place(move.piece, move.init)
+	} else {
+		b.Place(move.piece, move.init)
+		b.Place(-1, move.final)
+		if move.piece.IsPawn() && u.enPassant != nil && move.final.Equal(u.enPassant) {
+			b.Place(u.captured, &Pos{move.init.GetRank(), move.final.GetFile()})
+		} else {
+			b.Place(u.captured, move.final)
+		}
+	}
+
+	b.turn = !b.turn
+	b.castling = u.castling
+	b.enPassant = u.enPassant
+	b.halfMoves = u.halfMoves
+	b.fullMoves = u.fullMoves
+	b.inCheck = u.inCheck
+	b.hash = u.hash
+	return move, nil
+}
+
+// pushNull passes the move to the other side, for null move pruning. The
+// move clock starts again, as positions before a null move must not count
+// as repetitions. Only popNull takes it back.
+func (b *Board) pushNull() {
+	b.undos = append(b.undos, undo{
+		captured:  -1,
+		castling:  b.castling,
+		enPassant: b.enPassant,
+		halfMoves: b.halfMoves,
+		fullMoves: b.fullMoves,
+		inCheck:   b.inCheck,
+		hash:      b.hash,
+	})
+	b.hash ^= b.stateKey()
+	b.enPassant = nil
+	b.hash ^= b.stateKey() ^ utils.BLACK_TO__MOVE
+	b.halfMoves = 0
+	b.turn = !b.turn
+	b.inCheck = false
+}
+
+func (b *Board) popNull() {
+	u := b.undos[len(b.undos)-1]
+	b.undos = b.undos[:len(b.undos)-1]
+	b.turn = !b.turn
+	b.enPassant = u.enPassant
+	b.halfMoves = u.halfMoves
+	b.inCheck = u.inCheck
+	b.hash = u.hash
+}
+
+// hasPieces tells whether side has anything besides its king and pawns,
+// without which null move pruning is fooled by zugzwang.
+func (b *Board) hasPieces(side bool) bool {
+	base := WHITE_KING
+	if side {
+		base = BLACK_KING
+	}
+	return b.pieces[base+WHITE_QUEEN]|b.pieces[base+WHITE_ROOK]|b.pieces[base+WHITE_BISHOP]|b.pieces[base+WHITE_KNIGHT] != 0
+}
+
+// Clone returns a copy of the board that shares no state with it, for
+// searching the same position from several goroutines.
+func (b *Board) Clone() *Board {
+	clone := *b
+	clone.undos = slices.Clone(b.undos)
+	return &clone
+}
+
+// Moves returns the moves played with Push or MakeMove since the position
+// was set up, oldest first.
+func (b *Board) Moves() []Move {
+	moves := make([]Move, len(b.undos))
+	for i, u := range b.undos {
+		moves[i] = u.move
+	}
+	return moves
+}
+
+// Turn returns the side to move, true for black.
+func (b *Board) Turn() bool {
+	return b.turn
+}
+
+// MoveNumber returns the fullmove number, starting at 1 and incremented
+// after each black move.
+func (b *Board) MoveNumber() int {
+	return b.fullMoves
+}
+
+// castlingRightsLostAt returns the rights that are lost once a piece moves
+// from or to pos, i.e. when a castling rook leaves its square or is captured
+// there.
+func (b *Board) castlingRightsLostAt(pos Position) CastlingRights {
+	var lost CastlingRights
+	for side, homeRank := range [2]int16{0, 7} {
+		if pos.GetRank() != homeRank {
+			continue
+		}
+		for i, kingside := range [2]bool{true, false} {
+			if pos.GetFile() == b.rookFiles[side][i] {
+				lost |= castlingRight(side == 1, kingside)
+			}
+		}
+	}
+	return lost
+}
+
+// MakeMove plays the move on the board, promoting to a queen when a pawn
+// reaches the last rank. Use MakeMoveWithPromotion to underpromote.
 func (b *Board) MakeMove(p Piece, init Position, final Position) error {
+	return b.MakeMoveWithPromotion(p, init, final, -1)
+}
+
+// MakeMoveWithPromotion plays the move on the board, replacing a pawn that
+// reaches the last rank with promotion. A promotion of -1 means a queen.
+func (b *Board) MakeMoveWithPromotion(p Piece, init Position, final Position, promotion Piece) error {
 	if legal, err := b.IsLegal(p, init, final); !legal {
 		return errors.New("illegal move: " + err.Error())
 	}
-	b.Place(p, final)
-	b.Place(-1, init)
-	b.turn = !b.turn
+	if p.IsPawn() && (final.GetRank() == 0 || final.GetRank() == 7) {
+		promotions := promotionPieces(p.Color())
+		if promotion == -1 {
+			promotion = promotions[0]
+		}
+		if !slices.Contains(promotions[:], promotion) {
+			return errors.New("illegal move: pawn can't promote to that piece")
+		}
+	} else if promotion != -1 {
+		return errors.New("illegal move: only a pawn reaching the last rank can promote")
+	}
+	move := Move{p, init, final, promotion, false}
+	if castle, ok := b.castlingMove(p, init, final); ok {
+		move = castle
+	}
+	b.makeMove(move)
+	return nil
+}
+
+// makeMove plays a move known to be legal and updates inCheck.
+func (b *Board) makeMove(move Move) {
+	b.Push(move)
 	oppositionKing := WHITE_KING
-	if !p.Color() {
+	if !move.piece.Color() {
 		oppositionKing = BLACK_KING
 	}
 	pos := b.GetPiecePositions(oppositionKing)[0]
-	if b.IsAttackedBySide(pos, p.Color()) {
+	if b.IsAttackedBySide(pos, move.piece.Color()) {
 		b.inCheck = true
 	} else {
 		b.inCheck = false
 	}
-	b.PrintBoard()
-	return nil
 }
 
 func (b *Board) PrintBoard() {
@@ -196,34 +538,11 @@ func (b *Board) PrintBoard() {
 
 // checks if pos is attacked by side
 func (b *Board) IsAttackedBySide(pos Position, side bool) bool {
-	startingPiece := WHITE_KING
-	if side {
-		startingPiece = BLACK_KING
-	}
-	for piece := startingPiece; piece < startingPiece+6; piece += 1 {
-		if b.IsAttackedByPiece(piece, pos) {
-			return true
-		}
-	}
-	return false
+	return b.attackersOf(squareIndex(pos), side) != 0
 }
 
 func (b *Board) IsAttackedByPiece(piece Piece, tar Position) bool {
-	var i, j int16
-	for i = 0; i < 8; i++ {
-		for j = 0; j < 8; j++ {
-			if b.squares[i][j] == piece {
-				if i == tar.GetRank() && j == tar.GetFile() {
-					continue
-				}
-				var pos Position = &Pos{i, j}
-				if b.IsAttackedByPieceWithPos(piece)(b, piece, pos, tar) {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	return b.attackersOf(squareIndex(tar), piece.Color())&b.pieces[piece] != 0
 }
 
 func (b *Board) IsAttackedByPieceWithPos(piece Piece) func(*Board, Piece, Position, Position) bool {
@@ -263,141 +582,135 @@ func (b *Board) GenerateMovesForPiece(piece Piece) func(*Board, Position) []Move
 }
 
 func (b *Board) GenerateMoves(side bool) []Move {
-	var moves []Move
-	if side {
-		// for _, piece := range BLACK_PIECES {
-		// 	positions := b.GetPiecePositions(piece)
-		// 	for _, pos := range positions {
-		// 		moves = append(moves, b.GenerateMovesForPiece(piece)(b, pos)...)
-		// 	}
-		// }
-		var i, j int16
-		for i = 0; i < 8; i++ {
-			for j = 0; j < 8; j++ {
-				if !b.IsEmpty(&Pos{i, j}) && b.Get(&Pos{i, j}).Color() {
-					moves = append(moves, b.GenerateMovesForPiece(b.Get(&Pos{i, j}))(b, &Pos{i, j})...)
-				}
-			}
-		}
-	} else {
-		// for _, piece := range WHITE_PIECES {
-		// 	positions := b.GetPiecePositions(piece)
-		// 	for _, pos := range positions {
-		// 		moves = append(moves, b.GenerateMovesForPiece(piece)(b, pos)...)
-		// 	}
-		// }
-
-		var i, j int16
-		for i = 0; i < 8; i++ {
-			for j = 0; j < 8; j++ {
-				if !b.IsEmpty(&Pos{i, j}) && !b.Get(&Pos{i, j}).Color() {
-					moves = append(moves, b.GenerateMovesForPiece(b.Get(&Pos{i, j}))(b, &Pos{i, j})...)
-				}
-			}
-		}
+	moves := make([]Move, 0, 48)
+	own := b.occupied[sideIndex(side)]
+	for own != 0 {
+		moves = b.appendMovesFrom(moves, own.PopLSB())
 	}
-	var legalMoves []Move
+	legalMoves := moves[:0]
 	for _, move := range moves {
-		if legal, err := b.IsLegal(move.piece, move.init, move.final); (err == nil) && legal {
+		if b.keepsKingSafe(move) {
 			legalMoves = append(legalMoves, move)
 		}
 	}
-
 	return legalMoves
 }
 
-func GenerateKingMoves(board *Board, pos Position) []Move {
-	x := pos.GetRank()
-	y := pos.GetFile()
-	finalPositions := []Position{&Pos{x - 1, y}, &Pos{x + 1, y}, &Pos{x, y - 1}, &Pos{x, y + 1}, &Pos{x - 1, y - 1}, &Pos{x - 1, y + 1}, &Pos{x + 1, y + 1}, &Pos{x + 1, y - 1}}
-	var moves []Move
-	for _, finalPos := range finalPositions {
-		if finalPos.IsValid() {
-			moves = append(moves, Move{board.Get(pos), pos, finalPos})
-		}
+// keepsKingSafe tells whether a generated move leaves the mover's king out
+// of check, and for castling whether the king may pass where it goes.
+func (b *Board) keepsKingSafe(move Move) bool {
+	if move.castling && b.canCastle(move) != nil {
+		return false
 	}
-	return moves
+	b.Push(move)
+	inCheck := b.InCheck(move.piece.Color())
+	b.Pop()
+	return !inCheck
 }
 
-func GenerateRookMoves(board *Board, pos Position) []Move {
-	piece := board.Get(pos)
-	x := pos.GetRank()
-	y := pos.GetFile()
-	var finalPositions []Position
-	add := func(x int16, y int16) {
-		if board.Get(&Pos{x, y}) != -1 {
-			if board.Get(&Pos{x, y}).Color() != piece.Color() {
-				finalPositions = append(finalPositions, &Pos{x, y})
-			}
-			return
-		}
-		finalPositions = append(finalPositions, &Pos{x, y})
+// appendMovesFrom appends the pseudo-legal moves of the piece on sq.
+func (b *Board) appendMovesFrom(moves []Move, sq int) []Move {
+	piece := b.squares[sq/8][sq%8]
+	own := b.occupied[sideIndex(piece.Color())]
+	switch {
+	case piece.IsKing():
+		moves = appendMoves(moves, piece, sq, kingAttacks[sq]&^own)
+		return b.appendCastlingMoves(moves, piece, sq)
+	case piece == WHITE_QUEEN || piece == BLACK_QUEEN:
+		return appendMoves(moves, piece, sq, (rookAttacks(sq, b.all())|bishopAttacks(sq, b.all()))&^own)
+	case piece == WHITE_ROOK || piece == BLACK_ROOK:
+		return appendMoves(moves, piece, sq, rookAttacks(sq, b.all())&^own)
+	case piece == WHITE_BISHOP || piece == BLACK_BISHOP:
+		return appendMoves(moves, piece, sq, bishopAttacks(sq, b.all())&^own)
+	case piece == WHITE_KNIGHT || piece == BLACK_KNIGHT:
+		return appendMoves(moves, piece, sq, knightAttacks[sq]&^own)
+	case piece.IsPawn():
+		return b.appendPawnMoves(moves, piece, sq)
 	}
-	var itr int16
-	for itr = x + 1; itr < 8; itr++ {
-		add(itr, y)
+	return moves
+}
+
+// appendMoves appends a move of piece from sq to every square in targets.
+func appendMoves(moves []Move, piece Piece, sq int, targets Bitboard) []Move {
+	for targets != 0 {
+		moves = append(moves, Move{piece, positionOf(sq), positionOf(targets.PopLSB()), -1, false})
 	}
-	for itr = y + 1; itr < 8; itr++ {
-		add(x, itr)
+	return moves
+}
+
+// appendCastlingMoves appends castling candidates, keepsKingSafe and
+// IsLegal decide whether they can actually be played.
+func (b *Board) appendCastlingMoves(moves []Move, piece Piece, sq int) []Move {
+	homeRank := 0
+	if piece.Color() {
+		homeRank = 7
 	}
-	for itr = x - 1; itr >= 0; itr-- {
-		add(itr, y)
+	if sq/8 != homeRank {
+		return moves
 	}
-	for itr = y - 1; itr >= 0; itr-- {
-		add(x, itr)
+	if b.castling&castlingRight(piece.Color(), true) != 0 {
+		moves = append(moves, Move{piece, positionOf(sq), positionOf(homeRank*8 + 6), -1, true})
 	}
-	var moves []Move
-	for _, finalPos := range finalPositions {
-		moves = append(moves, Move{board.Get(pos), pos, finalPos})
+	if b.castling&castlingRight(piece.Color(), false) != 0 {
+		moves = append(moves, Move{piece, positionOf(sq), positionOf(homeRank*8 + 2), -1, true})
 	}
 	return moves
 }
 
-func GenerateBishopMoves(board *Board, pos Position) []Move {
-	piece := board.Get(pos)
-	x := pos.GetRank()
-	y := pos.GetFile()
-	var finalPositions []Position
-	add := func(x int16, y int16) {
-		if board.Get(&Pos{x, y}) != -1 {
-			if board.Get(&Pos{x, y}).Color() != piece.Color() {
-				finalPositions = append(finalPositions, &Pos{x, y})
-			}
-			return
+// pawnStartRank is the rank the pawns of side start on, the only one they
+// may move two squares from.
+func pawnStartRank(side bool) int16 {
+	if side {
+		return 6
+	}
+	return 1
+}
+
+func (b *Board) appendPawnMoves(moves []Move, piece Piece, sq int) []Move {
+	side := piece.Color()
+	forward, startRank := 8, 1
+	if side {
+		forward, startRank = -8, 6
+	}
+	targets := pawnAttacks[sideIndex(side)][sq] & b.occupied[sideIndex(!side)]
+	if b.enPassant != nil {
+		targets |= pawnAttacks[sideIndex(side)][sq] & squareBit(squareIndex(b.enPassant))
+	}
+	if one := sq + forward; !b.all().Has(one) {
+		targets |= squareBit(one)
+		if two := one + forward; sq/8 == startRank && !b.all().Has(two) {
+			targets |= squareBit(two)
 		}
-		finalPositions = append(finalPositions, &Pos{x, y})
-	}
-	var xitr, yitr int16 = x + 1, y + 1
-	for xitr < 8 && yitr < 8 {
-		add(xitr, yitr)
-		xitr++
-		yitr++
-	}
-	xitr, yitr = x-1, y-1
-	for xitr >= 0 && yitr >= 0 {
-		add(xitr, yitr)
-		xitr--
-		yitr--
-	}
-	xitr, yitr = x+1, y-1
-	for xitr < 8 && yitr >= 0 {
-		add(xitr, yitr)
-		xitr++
-		yitr--
-	}
-	xitr, yitr = x-1, y+1
-	for xitr >= 0 && yitr < 8 {
-		add(xitr, yitr)
-		xitr--
-		yitr++
 	}
-	var moves []Move
-	for _, finalPos := range finalPositions {
-		moves = append(moves, Move{board.Get(pos), pos, finalPos})
+	for targets != 0 {
+		to := targets.PopLSB()
+		if to/8 == 0 || to/8 == 7 {
+			for _, promotion := range promotionPieces(side) {
+				moves = append(moves, Move{piece, positionOf(sq), positionOf(to), promotion, false})
+			}
+			continue
+		}
+		moves = append(moves, Move{piece, positionOf(sq), positionOf(to), -1, false})
 	}
 	return moves
 }
 
+func GenerateKingMoves(board *Board, pos Position) []Move {
+	sq, piece := squareIndex(pos), board.Get(pos)
+	moves := appendMoves(nil, piece, sq, kingAttacks[sq]&^board.occupied[sideIndex(piece.Color())])
+	return board.appendCastlingMoves(moves, piece, sq)
+}
+
+func GenerateRookMoves(board *Board, pos Position) []Move {
+	sq, piece := squareIndex(pos), board.Get(pos)
+	return appendMoves(nil, piece, sq, rookAttacks(sq, board.all())&^board.occupied[sideIndex(piece.Color())])
+}
+
+func GenerateBishopMoves(board *Board, pos Position) []Move {
+	sq, piece := squareIndex(pos), board.Get(pos)
+	return appendMoves(nil, piece, sq, bishopAttacks(sq, board.all())&^board.occupied[sideIndex(piece.Color())])
+}
+
 func GenerateQueenMoves(board *Board, pos Position) []Move {
 	var moves []Move
 	moves = append(moves, GenerateRookMoves(board, pos)...)
@@ -406,70 +719,32 @@ func GenerateQueenMoves(board *Board, pos Position) []Move {
 }
 
 func GenerateKnightMoves(board *Board, pos Position) []Move {
-	x := pos.GetRank()
-	y := pos.GetFile()
-	finalPositions := []Position{&Pos{x + 1, y + 2}, &Pos{x + 1, y - 2}, &Pos{x + 2, y - 1}, &Pos{x + 2, y + 1}, &Pos{x - 1, y - 2}, &Pos{x - 1, y + 2}, &Pos{x - 2, y - 1}, &Pos{x - 2, y + 1}}
-	var moves []Move
-	for _, finalPos := range finalPositions {
-		if finalPos.IsValid() {
-			moves = append(moves, Move{board.Get(pos), pos, finalPos})
-		}
-	}
-	return moves
+	sq, piece := squareIndex(pos), board.Get(pos)
+	return appendMoves(nil, piece, sq, knightAttacks[sq]&^board.occupied[sideIndex(piece.Color())])
 }
 
 func GeneratePawnMoves(board *Board, pos Position) []Move {
-	x := pos.GetRank()
-	y := pos.GetFile()
-	var finalPositions []Position
-	if board.Get(pos).Color() {
-		pos := &Pos{x - 1, y}
-		if pos.IsValid() && board.Get(pos) == -1 {
-			finalPositions = append(finalPositions, &Pos{x - 1, y})
-		}
-		pos1 := &Pos{x - 2, y}
-		if x == 6 && pos.IsValid() && board.Get(pos) == -1 && pos1.IsValid() && board.Get(pos1) == -1 {
-			finalPositions = append(finalPositions, &Pos{x - 2, y})
-		}
-		pos2 := &Pos{x - 1, y - 1}
-		if (pos2).IsValid() && !board.IsEmpty(pos2) && !board.Get(pos2).Color() {
-			finalPositions = append(finalPositions, &Pos{x - 1, y - 1})
-		}
-		pos3 := &Pos{x - 1, y + 1}
-		if (pos3).IsValid() && !board.IsEmpty(pos3) && !board.Get(pos3).Color() {
-			finalPositions = append(finalPositions, &Pos{x - 1, y + 1})
-		}
-	} else {
-		pos := &Pos{x + 1, y}
-		if pos.IsValid() && board.Get(pos) == -1 {
-			finalPositions = append(finalPositions, &Pos{x + 1, y})
-		}
-		pos1 := &Pos{x + 2, y}
-		if x == 1 && pos.IsValid() && board.Get(&Pos{x + 1, y}) == -1 && pos1.IsValid() && board.Get(&Pos{x + 2, y}) == -1 {
-			finalPositions = append(finalPositions, &Pos{x + 2, y})
-		}
-		pos2 := &Pos{x + 1, y - 1}
-		if (pos2).IsValid() && !board.IsEmpty(pos2) && board.Get(pos2).Color() {
-			finalPositions = append(finalPositions, &Pos{x + 1, y - 1})
-		}
-		pos3 := &Pos{x + 1, y + 1}
-		if (pos3).IsValid() && !board.IsEmpty(pos3) && board.Get(pos3).Color() {
-			finalPositions = append(finalPositions, &Pos{x + 1, y + 1})
-		}
-	}
-	var moves []Move
-	for _, finalPos := range finalPositions {
-		if finalPos.IsValid() {
-			moves = append(moves, Move{board.Get(pos), pos, finalPos})
-		}
+	return board.appendPawnMoves(nil, board.Get(pos), squareIndex(pos))
+}
+
+// promotionPieces lists the pieces a pawn of the given side can promote to.
+func promotionPieces(side bool) [4]Piece {
+	if side {
+		return [4]Piece{BLACK_QUEEN, BLACK_ROOK, BLACK_BISHOP, BLACK_KNIGHT}
 	}
-	return moves
+	return [4]Piece{WHITE_QUEEN, WHITE_ROOK, WHITE_BISHOP, WHITE_KNIGHT}
 }
 
+// Move is piece going from init to final, promotion being -1 unless a pawn
+// promotes. For castling final is where the king lands, on the g or c file,
+// and castling tells it apart from a plain king move in Chess960. See
+// NewMove and the accessors in move.go.
 type Move struct {
-	piece Piece
-	init  Position
-	final Position
+	piece     Piece
+	init      Position
+	final     Position
+	promotion Piece
+	castling  bool
 }
 
 type Position interface {
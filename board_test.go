@@ -0,0 +1,317 @@
+package chessEngine
+
+import (
+	"strings"
+	"testing"
+)
+
+// loadBoard sets up fen, failing the test if it isn't valid.
+func loadBoard(t *testing.T, fen string) *Board {
+	t.Helper()
+	var board Board
+	if err := board.LoadFEN(fen); err != nil {
+		t.Fatal(err)
+	}
+	return &board
+}
+
+func square(t *testing.T, s string) Position {
+	t.Helper()
+	pos, err := parseSquare(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pos
+}
+
+// play makes the moves, each written as its two squares like "e1g1",
+// failing the test at the first illegal one.
+func play(t *testing.T, b *Board, moves ...string) {
+	t.Helper()
+	for _, move := range moves {
+		init, final := square(t, move[:2]), square(t, move[2:4])
+		if err := b.MakeMove(b.Get(init), init, final); err != nil {
+			t.Fatalf("%s: %v", move, err)
+		}
+	}
+}
+
+// fenField returns field i of the board's FEN: 0 for the pieces, 2 for the
+// castling rights, 3 for the en passant square.
+func fenField(b *Board, i int) string {
+	return strings.Fields(b.FEN())[i]
+}
+
+func TestCastlingRights(t *testing.T) {
+	const fen = "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	tests := []struct {
+		name  string
+		moves []string
+		want  string
+	}{
+		{"king move", []string{"e1e2"}, "kq"},
+		{"king there and back", []string{"e1d1", "e8d8", "d1e1"}, "-"},
+		{"kingside rook move", []string{"h1h2"}, "Qkq"},
+		{"queenside rook move", []string{"a1b1", "a8b8"}, "Kk"},
+		{"rook capture", []string{"a1a8"}, "Kk"},
+		{"rook captured in the corner", []string{"e1d2", "h8h1"}, "q"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board := loadBoard(t, fen)
+			play(t, board, test.moves...)
+			if got := fenField(board, 2); got != test.want {
+				t.Errorf("castling rights %q, expected %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCastlingLegality(t *testing.T) {
+	tests := []struct {
+		name, fen, move string
+		legal           bool
+	}{
+		{"kingside", "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1g1", true},
+		{"queenside", "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1c1", true},
+		{"out of check", "4k3/4r3/8/8/8/8/8/R3K2R w KQ - 0 1", "e1g1", false},
+		{"through check", "4k3/5r2/8/8/8/8/8/R3K2R w KQ - 0 1", "e1g1", false},
+		{"into check", "4k3/6r1/8/8/8/8/8/R3K2R w KQ - 0 1", "e1g1", false},
+		{"queenside through check", "3rk3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1c1", false},
+		{"rook passing an attacked square", "1r2k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1c1", true},
+		{"black through check", "r3k2r/8/8/8/8/8/8/3RK3 b kq - 0 1", "e8c8", false},
+		{"black kingside", "r3k2r/8/8/8/8/8/8/3RK3 b kq - 0 1", "e8g8", true},
+		{"no right", "4k3/8/8/8/8/8/8/R3K2R w Q - 0 1", "e1g1", false},
+		{"blocked", "4k3/8/8/8/8/8/8/RN2K2R w KQ - 0 1", "e1c1", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board := loadBoard(t, test.fen)
+			init, final := square(t, test.move[:2]), square(t, test.move[2:4])
+			legal, err := board.IsLegal(board.Get(init), init, final)
+			if legal != test.legal {
+				t.Errorf("IsLegal(%s) = %v, %v", test.move, legal, err)
+			}
+		})
+	}
+}
+
+func TestCastlingMovesRook(t *testing.T) {
+	const fen = "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	board := loadBoard(t, fen)
+	play(t, board, "e1g1", "e8c8")
+	if got := fenField(board, 0); got != "2kr3r/8/8/8/8/8/8/R4RK1" {
+		t.Errorf("after O-O O-O-O: %s", got)
+	}
+	if got := fenField(board, 2); got != "-" {
+		t.Errorf("castling rights %q left after castling", got)
+	}
+
+	board = loadBoard(t, fen)
+	play(t, board, "e1c1", "e8g8")
+	if got := fenField(board, 0); got != "r4rk1/8/8/8/8/8/8/2KR3R" {
+		t.Errorf("after O-O-O O-O: %s", got)
+	}
+}
+
+// hasMove tells whether moves has one from the first square of move to
+// the second, written like "e5d6".
+func hasMove(moves []Move, move string) bool {
+	for _, m := range moves {
+		if squareNotation(m.init)+squareNotation(m.final) == move {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEnPassant(t *testing.T) {
+	board := loadBoard(t, "4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1")
+	play(t, board, "d7d5")
+	if got := fenField(board, 3); got != "d6" {
+		t.Fatalf("en passant square %q after d5, expected d6", got)
+	}
+	if !hasMove(board.GenerateMoves(false), "e5d6") {
+		t.Error("exd6 e.p. not generated")
+	}
+	if legal, err := board.IsLegal(WHITE_PAWN, square(t, "e5"), square(t, "d6")); !legal {
+		t.Errorf("exd6 e.p. illegal: %v", err)
+	}
+	play(t, board, "e5d6")
+	if got := fenField(board, 0); got != "4k3/8/3P4/8/8/8/8/4K3" {
+		t.Errorf("after exd6 e.p.: %s", got)
+	}
+	if got := fenField(board, 3); got != "-" {
+		t.Errorf("en passant square %q left after the capture", got)
+	}
+
+	// black takes en passant too
+	board = loadBoard(t, "4k3/8/8/8/3p4/8/4P3/4K3 w - - 0 1")
+	play(t, board, "e2e4")
+	if got := fenField(board, 3); got != "e3" {
+		t.Fatalf("en passant square %q after e4, expected e3", got)
+	}
+	play(t, board, "d4e3")
+	if got := fenField(board, 0); got != "4k3/8/8/8/8/4p3/8/4K3" {
+		t.Errorf("after dxe3 e.p.: %s", got)
+	}
+
+	// the chance is gone after any other move
+	board = loadBoard(t, "4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1")
+	play(t, board, "d7d5", "e1e2")
+	if got := fenField(board, 3); got != "-" {
+		t.Errorf("en passant square %q left a move later", got)
+	}
+	play(t, board, "e8e7")
+	if legal, _ := board.IsLegal(WHITE_PAWN, square(t, "e5"), square(t, "d6")); legal {
+		t.Error("exd6 e.p. legal a move too late")
+	}
+	if hasMove(board.GenerateMoves(false), "e5d6") {
+		t.Error("exd6 e.p. generated a move too late")
+	}
+
+	// the en passant square is part of the position
+	with := loadBoard(t, "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1")
+	without := loadBoard(t, "4k3/8/8/3pP3/8/8/8/4K3 w - - 0 1")
+	if with.Hash() == without.Hash() {
+		t.Error("en passant square not hashed")
+	}
+}
+
+func TestPawnDoubleStep(t *testing.T) {
+	board := loadBoard(t, "4k3/8/8/8/8/4P3/8/4K3 w - - 0 1")
+	if legal, _ := board.IsLegal(WHITE_PAWN, square(t, "e3"), square(t, "e5")); legal {
+		t.Error("e3-e5 legal")
+	}
+	if hasMove(board.GenerateMoves(false), "e3e5") {
+		t.Error("e3-e5 generated")
+	}
+	board = loadBoard(t, "4k3/8/3p4/8/8/8/8/4K3 b - - 0 1")
+	if legal, _ := board.IsLegal(BLACK_PAWN, square(t, "d6"), square(t, "d4")); legal {
+		t.Error("d6-d4 legal")
+	}
+
+	// a single step sets no en passant square
+	board = loadBoard(t, "4k3/8/8/8/8/4P3/8/4K3 w - - 0 1")
+	play(t, board, "e3e4")
+	if got := fenField(board, 3); got != "-" {
+		t.Errorf("en passant square %q after e3-e4", got)
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	const fen = "3n3k/4P3/8/8/8/8/8/K7 w - - 0 1"
+	board := loadBoard(t, fen)
+	promotions := map[string][]Piece{}
+	for _, move := range board.GenerateMoves(false) {
+		if squareNotation(move.init) == "e7" {
+			promotions[squareNotation(move.final)] = append(promotions[squareNotation(move.final)], move.promotion)
+		}
+	}
+	for _, final := range []string{"e8", "d8"} {
+		if got := promotions[final]; len(got) != 4 {
+			t.Errorf("promotions on %s generated: %v, expected 4", final, got)
+		}
+	}
+
+	board = loadBoard(t, fen)
+	if err := board.MakeMoveWithPromotion(WHITE_PAWN, square(t, "e7"), square(t, "d8"), WHITE_KNIGHT); err != nil {
+		t.Fatal(err)
+	}
+	if got := fenField(board, 0); got != "3N3k/8/8/8/8/8/8/K7" {
+		t.Errorf("after exd8=N: %s", got)
+	}
+
+	board = loadBoard(t, fen)
+	play(t, board, "e7e8")
+	if got := fenField(board, 0); got != "3nQ2k/8/8/8/8/8/8/K7" {
+		t.Errorf("after e8 without a promotion: %s", got)
+	}
+
+	board = loadBoard(t, "k7/8/8/8/8/8/4p3/K7 b - - 0 1")
+	if err := board.MakeMoveWithPromotion(BLACK_PAWN, square(t, "e2"), square(t, "e1"), BLACK_ROOK); err != nil {
+		t.Fatal(err)
+	}
+	if got := fenField(board, 0); got != "k7/8/8/8/8/8/8/K3r3" {
+		t.Errorf("after e1=R: %s", got)
+	}
+
+	for _, test := range []struct {
+		name, fen, move string
+		promotion       Piece
+	}{
+		{"to a king", fen, "e7e8", WHITE_KING},
+		{"to a pawn", fen, "e7e8", WHITE_PAWN},
+		{"to a black piece", fen, "e7e8", BLACK_QUEEN},
+		{"before the last rank", "3n3k/8/4P3/8/8/8/8/K7 w - - 0 1", "e6e7", WHITE_QUEEN},
+		{"not a pawn", fen, "a1a2", WHITE_QUEEN},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			board := loadBoard(t, test.fen)
+			init, final := square(t, test.move[:2]), square(t, test.move[2:4])
+			err := board.MakeMoveWithPromotion(board.Get(init), init, final, test.promotion)
+			if err == nil || !strings.Contains(err.Error(), "promote") {
+				t.Errorf("promoting to %d: %v", test.promotion, err)
+			}
+			if board.FEN() != loadBoard(t, test.fen).FEN() {
+				t.Errorf("board changed to %s", board.FEN())
+			}
+		})
+	}
+}
+
+// boardState is what Pop has to restore, besides the pieces.
+type boardState struct {
+	fen       string
+	castling  CastlingRights
+	enPassant string
+	halfMoves int
+	fullMoves int
+	inCheck   bool
+}
+
+func stateOf(b *Board) boardState {
+	enPassant := "-"
+	if b.enPassant != nil {
+		enPassant = squareNotation(b.enPassant)
+	}
+	return boardState{b.FEN(), b.castling, enPassant, b.halfMoves, b.fullMoves, b.inCheck}
+}
+
+func TestPushPop(t *testing.T) {
+	board := loadBoard(t, "r3k2r/1P6/8/3pP3/8/8/8/R3K2R w KQkq d6 5 10")
+	if _, err := board.Pop(); err == nil {
+		t.Error("Pop with no move played succeeded")
+	}
+
+	// en passant, a capture giving check, a king move out of check,
+	// castling and a promotion
+	moves := []string{"e5d6", "a8a1", "e1e2", "e8g8", "b7b8"}
+	states := []boardState{stateOf(board)}
+	for _, move := range moves {
+		play(t, board, move)
+		states = append(states, stateOf(board))
+	}
+	if got := states[2]; !got.inCheck || got.castling != WHITE_KINGSIDE|BLACK_KINGSIDE || got.halfMoves != 0 {
+		t.Errorf("after Rxa1+: %+v", got)
+	}
+	if got := states[1]; got.enPassant != "-" || got.fen != "r3k2r/1P6/3P4/8/8/8/8/R3K2R b KQkq - 0 10" {
+		t.Errorf("after exd6 e.p.: %+v", got)
+	}
+
+	for i := len(moves) - 1; i >= 0; i-- {
+		move, err := board.Pop()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if squareNotation(move.init)+squareNotation(move.final) != moves[i] {
+			t.Errorf("Pop returned %v, expected %s", move, moves[i])
+		}
+		if got := stateOf(board); got != states[i] {
+			t.Errorf("taking back %s gave %+v, expected %+v", moves[i], got, states[i])
+		}
+	}
+	if _, err := board.Pop(); err == nil {
+		t.Error("Pop past the starting position succeeded")
+	}
+}
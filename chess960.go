@@ -0,0 +1,124 @@
+package chessEngine
+
+import (
+	"fmt"
+
+	"github.com/kishanshukla-2307/chess-engine/utils"
+)
+
+const (
+	CHESS960_POSITIONS = 960
+	// the Scharnagl number of the standard starting position
+	CHESS960_STANDARD = 518
+)
+
+// where the two knights go among the five squares left once the bishops
+// and queen are placed, by the Scharnagl number's knight digit
+var chess960Knights = [10][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}
+
+// InitializeChess960 sets up Chess960 starting position n, numbered from 0
+// to 959 the way Scharnagl does, 518 being the standard starting position.
+func (b *Board) InitializeChess960(n int) error {
+	if n < 0 || n >= CHESS960_POSITIONS {
+		return fmt.Errorf("no Chess960 position %d, expected 0 to %d", n, CHESS960_POSITIONS-1)
+	}
+	b.setUp(chess960BackRank(n))
+	b.chess960 = true
+	return nil
+}
+
+// chess960BackRank decodes a Scharnagl number: the light square bishop, the
+// dark square bishop, the queen on one of the six squares left, the knights
+// on two of the five left, then rook, king and rook on the last three.
+func chess960BackRank(n int) [8]Piece {
+	var backRank [8]Piece
+	for i := range backRank {
+		backRank[i] = -1
+	}
+	backRank[n%4*2+1] = WHITE_BISHOP
+	n /= 4
+	backRank[n%4*2] = WHITE_BISHOP
+	n /= 4
+	placeOnEmpty(&backRank, n%6, WHITE_QUEEN)
+	n /= 6
+	// the second knight first, so that the first one's square keeps its index
+	placeOnEmpty(&backRank, chess960Knights[n][1], WHITE_KNIGHT)
+	placeOnEmpty(&backRank, chess960Knights[n][0], WHITE_KNIGHT)
+	for _, piece := range []Piece{WHITE_ROOK, WHITE_KING, WHITE_ROOK} {
+		placeOnEmpty(&backRank, 0, piece)
+	}
+	return backRank
+}
+
+// placeOnEmpty puts piece on the nth empty square of the rank.
+func placeOnEmpty(backRank *[8]Piece, n int, piece Piece) {
+	for i := range backRank {
+		if backRank[i] != -1 {
+			continue
+		}
+		if n == 0 {
+			backRank[i] = piece
+			return
+		}
+		n--
+	}
+}
+
+// Chess960 reports whether the game is Fischer Random chess, which changes
+// how castling moves are written in coordinate notation.
+func (b *Board) Chess960() bool {
+	return b.chess960
+}
+
+func castlingRight(side bool, kingside bool) CastlingRights {
+	right := WHITE_KINGSIDE
+	if !kingside {
+		right = WHITE_QUEENSIDE
+	}
+	if side {
+		right <<= 2
+	}
+	return right
+}
+
+// castlingRookSquares returns where the rook castling with move starts and
+// where it lands, on the f file next to the king's g or the d next to c.
+func (b *Board) castlingRookSquares(move Move) (Position, Position) {
+	rank := int(move.init.GetRank())
+	rookFiles := b.rookFiles[sideIndex(move.piece.Color())]
+	if move.final.GetFile() == 6 {
+		return positionOf(rank*8 + int(rookFiles[0])), positionOf(rank*8 + 5)
+	}
+	return positionOf(rank*8 + int(rookFiles[1])), positionOf(rank*8 + 3)
+}
+
+// castlingMove tells whether a king move given by its squares means
+// castling, the king stepping onto its own castling rook the way Chess960
+// writes it or, outside Chess960, the king going two files along its home
+// rank, and returns the castling move.
+func (b *Board) castlingMove(p Piece, init Position, final Position) (Move, bool) {
+	if !p.IsKing() || init.GetRank() != final.GetRank() {
+		return Move{}, false
+	}
+	rank := int(init.GetRank())
+	for i, kingFile := range [2]int16{6, 2} {
+		rookFile := b.rookFiles[sideIndex(p.Color())][i]
+		onRook := final.GetFile() == rookFile && b.Get(final) == p+(WHITE_ROOK-WHITE_KING)
+		twoFiles := !b.chess960 && final.GetFile() == kingFile && utils.Abs(final.GetFile()-init.GetFile()) == 2
+		if onRook || twoFiles {
+			return Move{p, init, positionOf(rank*8 + int(kingFile)), -1, true}, true
+		}
+	}
+	return Move{}, false
+}
+
+// MoveNotation writes move in coordinate notation for this board, the same
+// as NotationFromMove except that Chess960 castling is written as the king
+// taking its own rook, e.g. "b1a1", which UCI_Chess960 expects.
+func (b *Board) MoveNotation(move Move) string {
+	if b.chess960 && move.castling {
+		rookFrom, _ := b.castlingRookSquares(move)
+		return squareNotation(move.init) + squareNotation(rookFrom)
+	}
+	return NotationFromMove(move)
+}
@@ -0,0 +1,86 @@
+package chessEngine
+
+import "testing"
+
+func TestChess960Positions(t *testing.T) {
+	seen := make(map[string]bool)
+	for n := 0; n < CHESS960_POSITIONS; n++ {
+		var board Board
+		if err := board.InitializeChess960(n); err != nil {
+			t.Fatal(err)
+		}
+		backRank := board.squares[0]
+		var bishops, rooks, king []int
+		for file, piece := range backRank {
+			switch piece {
+			case WHITE_BISHOP:
+				bishops = append(bishops, file)
+			case WHITE_ROOK:
+				rooks = append(rooks, file)
+			case WHITE_KING:
+				king = append(king, file)
+			}
+		}
+		if len(bishops) != 2 || bishops[0]%2 == bishops[1]%2 || len(king) != 1 || len(rooks) != 2 || king[0] < rooks[0] || king[0] > rooks[1] {
+			t.Errorf("position %d is not a Chess960 position: %s", n, board.FEN())
+		}
+		seen[board.FEN()] = true
+	}
+	if len(seen) != CHESS960_POSITIONS {
+		t.Errorf("%d different positions, expected %d", len(seen), CHESS960_POSITIONS)
+	}
+
+	var board Board
+	board.InitializeChess960(CHESS960_STANDARD)
+	if fen := board.FEN(); fen != START_FEN {
+		t.Errorf("position %d is %s, expected the standard one", CHESS960_STANDARD, fen)
+	}
+	if err := board.InitializeChess960(CHESS960_POSITIONS); err == nil {
+		t.Errorf("position %d accepted", CHESS960_POSITIONS)
+	}
+}
+
+func TestChess960Castling(t *testing.T) {
+	var board Board
+	// the king on f1 castles kingside by swapping places with the g1 rook
+	if err := board.LoadFEN("b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9"); err != nil {
+		t.Fatal(err)
+	}
+	move, err := ParseUCIMove(&board, "f1g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !move.IsCastling() || board.SAN(move) != "O-O" || board.MoveNotation(move) != "f1g1" {
+		t.Errorf("f1g1 read as %s, castling %t", board.SAN(move), move.IsCastling())
+	}
+	if _, err := ParseUCIMove(&board, "f1c1"); err == nil {
+		t.Errorf("blocked queenside castling accepted")
+	}
+	before := board.FEN()
+	board.Push(move)
+	if fen := board.FEN(); fen != "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRRKB b - - 2 9" {
+		t.Errorf("after O-O: %s", fen)
+	}
+	board.Pop()
+	if fen := board.FEN(); fen != before {
+		t.Errorf("after taking O-O back: %s", fen)
+	}
+
+	// an inner rook is written by its file, the outer one as K or Q
+	if err := board.LoadFEN("1r2k1r1/8/8/8/8/8/8/RR2K3 w Bg - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	if fen := board.FEN(); fen != "1r2k1r1/8/8/8/8/8/8/RR2K3 w Bk - 0 1" {
+		t.Errorf("X-FEN castling written as %s", fen)
+	}
+	move, err = board.ParseSAN("O-O-O")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notation := board.MoveNotation(move); notation != "e1b1" {
+		t.Errorf("O-O-O written as %s, expected e1b1", notation)
+	}
+	if notation := move.String(); notation != "e1c1" {
+		t.Errorf("O-O-O written as %s without Chess960, expected e1c1", notation)
+	}
+}
@@ -3,9 +3,9 @@ package chessEngine
 import (
 	"errors"
 	"fmt"
-	"math"
 	"math/rand/v2"
-	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/kishanshukla-2307/chess-engine/utils"
@@ -19,9 +19,26 @@ type Engine interface {
 // var _ Engine = (*NoobEngine)(nil)
 
 type NoobEngine struct {
-	board     Board
-	chess960  bool
-	enPassant bool
+	board       Board
+	chess960    bool
+	result      GameResult
+	termination Termination
+	// depth searched when Search is given no limits
+	depth int
+	stop  atomic.Bool
+	// the search score of the moves Run played, white's point of view, by
+	// their index in the board's moves
+	evals   map[int]Score
+	clock   timeControl
+	tt      *TranspositionTable
+	options SearchOptions
+}
+
+// timeControl is the clock Run plays on, see SetTimeControl.
+type timeControl struct {
+	base            time.Duration
+	inc             time.Duration
+	movesPerSession int
 }
 
 func NewNoobEngine(chess960 bool) (*NoobEngine, error) {
@@ -30,99 +47,121 @@ func NewNoobEngine(chess960 bool) (*NoobEngine, error) {
 	if err != nil {
 		return nil, err
 	}
+	tt, err := NewTranspositionTable(DEFAULT_HASH_MB)
+	if err != nil {
+		return nil, err
+	}
 	return &NoobEngine{board: board,
-		chess960: chess960}, nil
+		chess960: chess960,
+		depth:    DEFAULT_DEPTH,
+		tt:       tt,
+		options:  DefaultSearchOptions()}, nil
 }
 
+func NewNoobEngineFromFEN(fen string) (*NoobEngine, error) {
+	var board Board
+	err := board.LoadFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	tt, err := NewTranspositionTable(DEFAULT_HASH_MB)
+	if err != nil {
+		return nil, err
+	}
+	return &NoobEngine{board: board, chess960: board.chess960, depth: DEFAULT_DEPTH, tt: tt,
+		options: DefaultSearchOptions()}, nil
+}
+
+// SetHashSize resizes the transposition table to mb megabytes, forgetting
+// what it held.
+func (ne *NoobEngine) SetHashSize(mb int) error {
+	return ne.tt.Resize(mb)
+}
+
+// SetTimeControl makes Run play on a chess clock: base for each side, inc
+// added after every move and, unless movesPerSession is 0, base added again
+// every movesPerSession moves. Without a time control every move is searched
+// to the engine's depth.
+func (ne *NoobEngine) SetTimeControl(base, inc time.Duration, movesPerSession int) {
+	ne.clock = timeControl{base, inc, movesPerSession}
+}
+
+// Run plays a game against itself from the current position, thinking by
+// iterative deepening within the time control.
 func (ne *NoobEngine) Run() error {
-	move_no := 0
-	depth := 5
-	// reader := bufio.NewReader(os.Stdin)
+	clocks := [2]time.Duration{ne.clock.base, ne.clock.base}
+	played := [2]int{}
 	for {
-		if !ne.board.turn {
-			start := time.Now()
-			tree := NewNode(ne.board, depth)
-			eval, moves := tree.EvaluateTreeWithPruning(depth, false, -math.MaxFloat32, math.MaxFloat32)
-			// result := make(chan struct {
-			// 	float32
-			// 	Move
-			// })
-			// go tree.EvaluateTreeConcurrent(depth, false, result)
-			// response := <-result
-			// fmt.Println(response.float32)
-			// fmt.Println(response.Move)
-			fmt.Println(eval)
-			fmt.Println(moves[0])
-			move := moves[0]
-			err := ne.board.MakeMove(move.piece, move.init, move.final)
-			if err != nil {
-				fmt.Println(err.Error())
-			}
-			end := time.Now()
-			// if move_no > 0 {
-			// 	depth = 5
-			// 	fmt.Println("depth increased")
-			// }
-			fmt.Println("Time taken: ", end.Sub(start))
-		} else {
-			start := time.Now()
-			tree := NewNode(ne.board, depth)
-			eval, moves := tree.EvaluateTreeWithPruning(depth, true, -math.MaxFloat32, math.MaxFloat32)
-			// result := make(chan struct {
-			// 	float32
-			// 	Move
-			// })
-			// go tree.EvaluateTreeConcurrent(depth, false, result)
-			// response := <-result
-			// fmt.Println(response.float32)
-			// fmt.Println(response.Move)
-			fmt.Println(eval)
-			fmt.Println(moves[0])
-			move := moves[0]
-			err := ne.board.MakeMove(move.piece, move.init, move.final)
-			if err != nil {
-				fmt.Println(err.Error())
+		if result, termination := ne.board.Status(); result != ONGOING {
+			ne.result, ne.termination = result, termination
+			fmt.Printf("%s (%s)\n", result, termination)
+			return nil
+		}
+		side := sideIndex(ne.board.turn)
+		limits := SearchLimits{}
+		if ne.clock.base > 0 {
+			limits = SearchLimits{WhiteTime: clocks[0], BlackTime: clocks[1], WhiteInc: ne.clock.inc, BlackInc: ne.clock.inc}
+			if ne.clock.movesPerSession > 0 {
+				limits.MovesToGo = ne.clock.movesPerSession - played[side]%ne.clock.movesPerSession
 			}
+		}
 
-			end := time.Now()
-			fmt.Println("Time taken: ", end.Sub(start))
-			// tree := NewNode(ne.board, 3)
-			// eval, moves := tree.EvaluateTree(3, true)
-			// fmt.Println(eval)
-			// move := moves[0]
-			// err := ne.board.MakeMove(move.piece, move.init, move.final)
-			// if err != nil {
-			// 	fmt.Println(err.Error())
-			// }
-
-			// fmt.Println("Enter Black's Move: ")
-			// moveStr, _ := reader.ReadString('\n')
-			// move := strings.Split(moveStr, " ")
-			// piece, err := ne.PieceFromNotation(move[0])
-			// if err != nil {
-			// 	fmt.Println(err.Error())
-			// 	continue
-			// }
-			// init, err := ne.PositionFromNotation(move[1])
-			// if err != nil {
-			// 	fmt.Println(err.Error())
-			// 	continue
-			// }
-			// final, err := ne.PositionFromNotation(move[2])
-			// if err != nil {
-			// 	fmt.Println(err.Error())
-			// 	continue
-			// }
-			// err = ne.board.MakeMove(piece, init, final)
-			// if err != nil {
-			// 	fmt.Println(err.Error())
-			// 	continue
-			// }
+		start := time.Now()
+		var previous SearchInfo
+		ne.resetStop()
+		info := ne.Search(limits, func(info SearchInfo) {
+			fmt.Printf("depth %d eval %s nodes %d qnodes %d ebf %.1f fail low/high %d/%d time %s pv %s\n", info.Depth,
+				info.Eval, info.Nodes, info.QNodes, info.BranchingFactor(previous), info.FailLows, info.FailHighs, info.Time,
+				strings.Join(ne.board.SANLine(info.PV), " "))
+			previous = info
+		})
+		elapsed := time.Since(start)
+		fmt.Println("Time taken: ", elapsed)
+
+		if ne.clock.base > 0 {
+			clocks[side] -= elapsed
+			if clocks[side] < 0 {
+				ne.result, ne.termination = WHITE_WINS, TIME_FORFEIT
+				if side == 0 {
+					ne.result = BLACK_WINS
+				}
+				fmt.Printf("%s (%s)\n", ne.result, ne.termination)
+				return nil
+			}
+			clocks[side] += ne.clock.inc
+			played[side]++
+			if ne.clock.movesPerSession > 0 && played[side]%ne.clock.movesPerSession == 0 {
+				clocks[side] += ne.clock.base
+			}
 		}
-		move_no++
+		if ne.evals == nil {
+			ne.evals = make(map[int]Score)
+		}
+		ne.evals[len(ne.board.undos)] = info.Eval
+		ne.board.makeMove(info.PV[0])
+		ne.board.PrintBoard()
 	}
 }
 
+// Result returns how the last game played by Run ended.
+func (ne *NoobEngine) Result() (GameResult, Termination) {
+	return ne.result, ne.termination
+}
+
+// Board returns the position the engine is playing, with the moves that
+// led to it.
+func (ne *NoobEngine) Board() *Board {
+	return &ne.board
+}
+
+// Evals returns the search score of the moves played by Run, by their index
+// in Board().Moves(). Moves made any other way have none. The scores are in
+// centipawns from white's point of view, mates counted as Score describes;
+// String writes them as "0.35" or "#3".
+func (ne *NoobEngine) Evals() map[int]Score {
+	return ne.evals
+}
+
 func (ne *NoobEngine) PieceFromNotation(n string) (Piece, error) {
 	if ne.board.turn {
 		switch n {
@@ -159,15 +198,40 @@ func (ne *NoobEngine) PieceFromNotation(n string) (Piece, error) {
 	return -1, errors.New("Unsupported notation")
 }
 
-func (ne *NoobEngine) PositionFromNotation(pos string) (Position, error) {
-	file := (int16)(pos[0] - 'a')
-	rank, err := strconv.Atoi(string(pos[1]))
-	if err != nil {
-		return &Pos{}, nil
+// PromotionFromNotation reads a promotion suffix such as "=Q", "Q" or the
+// lowercase "q" used by coordinate notation, for the side to move.
+func (ne *NoobEngine) PromotionFromNotation(n string) (Piece, error) {
+	n = strings.TrimPrefix(n, "=")
+	if len(n) != 1 || strings.ToUpper(n) == "K" || strings.ToUpper(n) == "P" {
+		return -1, errors.New("Unsupported promotion notation")
 	}
-	rank--
-	var res Position = &Pos{(int16)(rank), file}
-	return res, nil
+	return ne.PieceFromNotation(strings.ToUpper(n))
+}
+
+// NotationFromMove writes the move in coordinate notation, e.g. "e2e4",
+// with a lowercase promotion suffix such as "e7e8q".
+func NotationFromMove(move Move) string {
+	notation := squareNotation(move.init) + squareNotation(move.final)
+	if move.promotion != -1 {
+		notation += strings.ToLower(string(pieceFENChars[move.promotion]))
+	}
+	return notation
+}
+
+// moveFromCoordinates finds the legal move written in coordinate notation,
+// as produced by MoveNotation.
+func (b *Board) moveFromCoordinates(notation string) (Move, error) {
+	for _, move := range b.GenerateMoves(b.turn) {
+		if b.MoveNotation(move) == notation {
+			return move, nil
+		}
+	}
+	return Move{}, errors.New("no legal move " + notation)
+}
+
+// PositionFromNotation reads a square such as "e4".
+func (ne *NoobEngine) PositionFromNotation(pos string) (Position, error) {
+	return parseSquare(pos)
 }
 
 // func (ne *NoobEngine) IsLegal(p Piece, init Position, final Position) (bool, error) {
@@ -268,10 +332,6 @@ func (ne *NoobEngine) PositionFromNotation(pos string) (Position, error) {
 // 	return nil
 // }
 
-func (ne *NoobEngine) MakeMoveByNotation(move string) error {
-	return nil
-}
-
 // func (ne *NoobEngine) MakeMove(p Piece, init Position, final Position) error {
 // 	if legal, err := ne.board.IsLegal(p, init, final); !legal {
 // 		return errors.New("illegal move: " + err.Error())
@@ -364,8 +424,7 @@ func (ne *NoobEngine) BestMove(side bool) Move {
 }
 
 func KingAttack(board *Board, piece Piece, orig Position, tar Position) bool {
-	diff := tar.Sub(orig)
-	return utils.Abs(diff.GetRank())|utils.Abs(diff.GetFile()) == 1
+	return kingAttacks[squareIndex(orig)].Has(squareIndex(tar))
 }
 
 func QueenAttack(board *Board, piece Piece, orig Position, tar Position) bool {
@@ -373,75 +432,29 @@ func QueenAttack(board *Board, piece Piece, orig Position, tar Position) bool {
 }
 
 func RookAttack(board *Board, piece Piece, orig Position, tar Position) bool {
-	if (tar.GetRank() == orig.GetRank()) || (tar.GetFile() == orig.GetFile()) {
-		if tar.GetRank() == orig.GetRank() {
-			start := min(tar.GetFile(), orig.GetFile()) + 1
-			for ; start < max(tar.GetFile(), orig.GetFile()); start++ {
-				if board.Get(&Pos{orig.GetRank(), start}) != -1 {
-					return false
-				}
-			}
-		} else {
-			start := min(tar.GetRank(), orig.GetRank()) + 1
-			for ; start < max(tar.GetRank(), orig.GetRank()); start++ {
-				if board.Get(&Pos{start, orig.GetFile()}) != -1 {
-					return false
-				}
-			}
-		}
-		return true
-	}
-	return false
+	return rookAttacks(squareIndex(orig), board.all()).Has(squareIndex(tar))
 }
 
 func BishopAttack(board *Board, piece Piece, orig Position, tar Position) bool {
-	diff := tar.Sub(orig)
-	if utils.Abs(diff.GetRank()) == utils.Abs(diff.GetFile()) {
-		var rank_ch int16 = -1
-		if diff.GetRank() > 0 {
-			rank_ch = 1
-		}
-		var file_ch int16 = -1
-		if diff.GetFile() > 0 {
-			file_ch = 1
-		}
-
-		rank := orig.GetRank() + rank_ch
-		file := orig.GetFile() + file_ch
-
-		for rank != tar.GetRank() && file != tar.GetFile() {
-			if board.squares[rank][file] != -1 {
-				return false
-			}
-			rank += rank_ch
-			file += file_ch
-		}
-		return true
-	}
-	return false
+	return bishopAttacks(squareIndex(orig), board.all()).Has(squareIndex(tar))
 }
 
 func KnightAttack(board *Board, piece Piece, orig Position, tar Position) bool {
-	diff := tar.Sub(orig)
-	return (utils.Abs(diff.GetRank()) == 2 && utils.Abs(diff.GetFile()) == 1) || (utils.Abs(diff.GetRank()) == 1 && utils.Abs(diff.GetFile()) == 2)
+	return knightAttacks[squareIndex(orig)].Has(squareIndex(tar))
 }
 
+// PawnAttack reports whether the pawn covers tar, whether or not there is
+// anything on it, so that empty squares like the ones a castling king
+// passes over are seen as attacked too.
 func PawnAttack(board *Board, piece Piece, orig Position, tar Position) bool {
-	diff := tar.Sub(orig)
-	if !piece.Color() {
-		if (diff.GetRank() == 1 && utils.Abs(diff.GetFile()) == 1) && !board.IsEmpty(tar) && board.Get(tar).Color() != piece.Color() {
-			return true
-		}
-	} else {
-		if (diff.GetRank() == -1 && utils.Abs(diff.GetFile()) == 1) && !board.IsEmpty(tar) && board.Get(tar).Color() != piece.Color() {
-			return true
-		}
-	}
-	return false
+	return pawnAttacks[sideIndex(piece.Color())][squareIndex(orig)].Has(squareIndex(tar))
 }
 
 func PawnMove(board *Board, piece Piece, orig Position, tar Position) bool {
 	diff := tar.Sub(orig)
+	if (utils.Abs(diff.GetRank()) == 1 && utils.Abs(diff.GetFile()) == 1) && board.enPassant != nil && tar.Equal(board.enPassant) {
+		return PawnAttack(board, piece, orig, tar)
+	}
 	if !piece.Color() {
 		if (diff.GetRank() == 1 && utils.Abs(diff.GetFile()) == 1) && !board.IsEmpty(tar) && board.Get(tar).Color() != piece.Color() {
 			return true
@@ -449,7 +462,7 @@ func PawnMove(board *Board, piece Piece, orig Position, tar Position) bool {
 		if (diff.GetRank() == 1 && diff.GetFile() == 0) && board.IsEmpty(tar) {
 			return true
 		}
-		if (diff.GetRank() == 2 && diff.GetFile() == 0) && board.IsEmpty(tar) && board.IsEmpty(tar.Sub(&Pos{1, 0})) {
+		if (diff.GetRank() == 2 && diff.GetFile() == 0) && orig.GetRank() == pawnStartRank(piece.Color()) && board.IsEmpty(tar) && board.IsEmpty(tar.Sub(&Pos{1, 0})) {
 			return true
 		}
 	} else {
@@ -459,7 +472,7 @@ func PawnMove(board *Board, piece Piece, orig Position, tar Position) bool {
 		if (diff.GetRank() == -1 && diff.GetFile() == 0) && board.IsEmpty(tar) {
 			return true
 		}
-		if (diff.GetRank() == -2 && diff.GetFile() == 0) && board.IsEmpty(tar) && board.IsEmpty(tar.Sub(&Pos{-1, 0})) {
+		if (diff.GetRank() == -2 && diff.GetFile() == 0) && orig.GetRank() == pawnStartRank(piece.Color()) && board.IsEmpty(tar) && board.IsEmpty(tar.Sub(&Pos{-1, 0})) {
 			return true
 		}
 	}
@@ -22,33 +22,27 @@ type Evaluator interface {
 type evaluator struct {
 }
 
-func (e *evaluator) Evaluate(board *Board, side bool) float32 {
-	// return float32(e.MaterialDifference(board))
-	return float32(e.MaterialDifference(board)) + 2*e.PieceDevelopment(board, side)
+// Evaluate scores the position from white's point of view.
+func (e *evaluator) Evaluate(board *Board, side bool) Score {
+	// return Score(e.MaterialDifference(board)) * PAWN_SCORE
+	return Score(e.MaterialDifference(board))*PAWN_SCORE + 2*e.PieceDevelopment(board, side)
 }
 
 func (e *evaluator) MaterialDifference(board *Board) int {
 	white := 0
 	black := 0
-	var i, j int16
-	for i = 0; i < 8; i++ {
-		for j = 0; j < 8; j++ {
-			piece := board.Get(&Pos{i, j})
-			if piece != -1 {
-				if piece.Color() {
-					black += PIECE_VALUE[piece]
-				} else {
-					white += PIECE_VALUE[piece]
-				}
-			}
-		}
+	for _, piece := range WHITE_PIECES {
+		white += PIECE_VALUE[piece] * board.pieces[piece].Count()
+	}
+	for _, piece := range BLACK_PIECES {
+		black += PIECE_VALUE[piece] * board.pieces[piece].Count()
 	}
 	return white - black
 }
 
-func (e *evaluator) PieceDevelopment(board *Board, side bool) float32 {
-	piece_penality := map[Piece]float32{WHITE_ROOK: 0.1, WHITE_BISHOP: 0.4, WHITE_KNIGHT: 0.5, WHITE_QUEEN: 0.1, WHITE_KING: 0.0}
-	penality := float32(0)
+func (e *evaluator) PieceDevelopment(board *Board, side bool) Score {
+	piece_penality := map[Piece]Score{WHITE_ROOK: 10, WHITE_BISHOP: 40, WHITE_KNIGHT: 50, WHITE_QUEEN: 10, WHITE_KING: 0}
+	penality := Score(0)
 	if !side {
 		if board.Get(&Pos{0, 1}) == WHITE_KNIGHT {
 			penality -= piece_penality[WHITE_KNIGHT]
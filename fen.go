@@ -0,0 +1,304 @@
+package chessEngine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const START_FEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+var fenPieces = map[byte]Piece{
+	'K': WHITE_KING, 'Q': WHITE_QUEEN, 'R': WHITE_ROOK, 'B': WHITE_BISHOP, 'N': WHITE_KNIGHT, 'P': WHITE_PAWN,
+	'k': BLACK_KING, 'q': BLACK_QUEEN, 'r': BLACK_ROOK, 'b': BLACK_BISHOP, 'n': BLACK_KNIGHT, 'p': BLACK_PAWN,
+}
+
+var pieceFENChars = []byte{'K', 'Q', 'R', 'B', 'N', 'P', 'k', 'q', 'r', 'b', 'n', 'p'}
+
+// LoadFEN replaces the position on the board with the one described by fen.
+// The halfmove clock and fullmove number may be omitted, in which case they
+// default to 0 and 1. On error the board is left untouched.
+func (b *Board) LoadFEN(fen string) error {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 || len(fields) > 6 {
+		return fmt.Errorf("invalid FEN: expected 4 to 6 fields, found %d", len(fields))
+	}
+
+	var board Board
+	if err := board.parsePlacement(fields[0]); err != nil {
+		return err
+	}
+
+	switch fields[1] {
+	case "w":
+		board.turn = false
+	case "b":
+		board.turn = true
+	default:
+		return fmt.Errorf("invalid FEN side to move: unexpected %q", fields[1])
+	}
+
+	if err := board.parseCastling(fields[2]); err != nil {
+		return err
+	}
+
+	if fields[3] != "-" {
+		pos, err := parseSquare(fields[3])
+		if err != nil {
+			return fmt.Errorf("invalid FEN en passant square: %s", err.Error())
+		}
+		if (board.turn && pos.GetRank() != 2) || (!board.turn && pos.GetRank() != 5) {
+			return fmt.Errorf("invalid FEN en passant square: %q is not on the expected rank", fields[3])
+		}
+		board.enPassant = pos
+	}
+
+	board.fullMoves = 1
+	if len(fields) > 4 {
+		n, err := parseFENCounter(fields[4])
+		if err != nil {
+			return fmt.Errorf("invalid FEN halfmove clock: %s", err.Error())
+		}
+		board.halfMoves = n
+	}
+	if len(fields) > 5 {
+		n, err := parseFENCounter(fields[5])
+		if err != nil {
+			return fmt.Errorf("invalid FEN fullmove number: %s", err.Error())
+		}
+		if n == 0 {
+			return errors.New("invalid FEN fullmove number: must be at least 1")
+		}
+		board.fullMoves = n
+	}
+
+	kingPos := board.GetPiecePositions(WHITE_KING)[0]
+	if board.turn {
+		kingPos = board.GetPiecePositions(BLACK_KING)[0]
+	}
+	board.inCheck = board.IsAttackedBySide(kingPos, !board.turn)
+	board.hash = board.computeHash()
+
+	*b = board
+	return nil
+}
+
+func (b *Board) parsePlacement(placement string) error {
+	ranks := strings.Split(placement, "/")
+	if len(ranks) != 8 {
+		return fmt.Errorf("invalid FEN piece placement: expected 8 ranks, found %d", len(ranks))
+	}
+	for i, row := range ranks {
+		rank := 7 - i
+		file := 0
+		for j := 0; j < len(row); j++ {
+			c := row[j]
+			if c >= '1' && c <= '8' {
+				for n := 0; n < int(c-'0') && file+n < 8; n++ {
+					b.squares[rank][file+n] = -1
+				}
+				file += int(c - '0')
+			} else if piece, ok := fenPieces[c]; ok {
+				if file < 8 {
+					b.squares[rank][file] = piece
+				}
+				if piece.IsPawn() && (rank == 0 || rank == 7) {
+					return fmt.Errorf("invalid FEN piece placement: pawn %q on rank %d", c, rank+1)
+				}
+				file++
+			} else {
+				return fmt.Errorf("invalid FEN piece placement: unexpected character %q in rank %d", c, rank+1)
+			}
+			if file > 8 {
+				return fmt.Errorf("invalid FEN piece placement: rank %d has more than 8 squares at %q", rank+1, c)
+			}
+		}
+		if file != 8 {
+			return fmt.Errorf("invalid FEN piece placement: rank %d has %d squares", rank+1, file)
+		}
+	}
+	b.syncBitboards()
+	if n := len(b.GetPiecePositions(WHITE_KING)); n != 1 {
+		return fmt.Errorf("invalid FEN piece placement: expected one white king, found %d", n)
+	}
+	if n := len(b.GetPiecePositions(BLACK_KING)); n != 1 {
+		return fmt.Errorf("invalid FEN piece placement: expected one black king, found %d", n)
+	}
+	return nil
+}
+
+// parseCastling reads the castling rights as KQkq, or as the files of the
+// castling rooks for Chess960 the way Shredder-FEN and X-FEN do, X-FEN
+// keeping K and Q for the outermost rook on either side of the king. Rights
+// that need a king or rook off the standard squares make the game Chess960.
+func (b *Board) parseCastling(castling string) error {
+	b.rookFiles = [2][2]int16{{7, 0}, {7, 0}}
+	if castling == "-" {
+		return nil
+	}
+	for i := 0; i < len(castling); i++ {
+		c := castling[i]
+		side := c >= 'a'
+		letter := c
+		if side {
+			letter -= 'a' - 'A'
+		}
+		homeRank, king := 0, WHITE_KING
+		if side {
+			homeRank, king = 7, BLACK_KING
+		}
+		rook := king + (WHITE_ROOK - WHITE_KING)
+		kingFile := int16(-1)
+		for file := int16(0); file < 8; file++ {
+			if b.squares[homeRank][file] == king {
+				kingFile = file
+			}
+		}
+
+		rookFile := int16(-1)
+		switch {
+		case letter == 'K':
+			for file := int16(7); file > kingFile && rookFile < 0; file-- {
+				if b.squares[homeRank][file] == rook {
+					rookFile = file
+				}
+			}
+		case letter == 'Q':
+			for file := int16(0); file < kingFile && rookFile < 0; file++ {
+				if b.squares[homeRank][file] == rook {
+					rookFile = file
+				}
+			}
+		case letter >= 'A' && letter <= 'H':
+			if b.squares[homeRank][letter-'A'] == rook {
+				rookFile = int16(letter - 'A')
+			}
+		default:
+			return fmt.Errorf("invalid FEN castling rights: unexpected character %q", c)
+		}
+		if kingFile < 0 || rookFile < 0 || rookFile == kingFile {
+			return fmt.Errorf("invalid FEN castling rights: no king and rook to castle with for %q", c)
+		}
+
+		kingside := rookFile > kingFile
+		right := castlingRight(side, kingside)
+		if b.castling&right != 0 {
+			return fmt.Errorf("invalid FEN castling rights: duplicate right %q", c)
+		}
+		b.castling |= right
+		if kingside {
+			b.rookFiles[sideIndex(side)][0] = rookFile
+		} else {
+			b.rookFiles[sideIndex(side)][1] = rookFile
+		}
+		if kingFile != 4 || (rookFile != 0 && rookFile != 7) {
+			b.chess960 = true
+		}
+	}
+	return nil
+}
+
+// castlingNotation writes a castling right for FEN the way X-FEN does: K
+// or Q when the rook is the outermost one on that side of the king, else
+// its file.
+func (b *Board) castlingNotation(side bool, kingside bool) byte {
+	homeRank, rook := 0, WHITE_ROOK
+	if side {
+		homeRank, rook = 7, BLACK_ROOK
+	}
+	letter, rookFile, outside, step := byte('K'), b.rookFiles[sideIndex(side)][0], int16(7), int16(-1)
+	if !kingside {
+		letter, rookFile, outside, step = 'Q', b.rookFiles[sideIndex(side)][1], 0, 1
+	}
+	for file := outside; file != rookFile; file += step {
+		if b.squares[homeRank][file] == rook {
+			letter = byte('A' + rookFile)
+			break
+		}
+	}
+	if side {
+		letter += 'a' - 'A'
+	}
+	return letter
+}
+
+func parseFENCounter(field string) (int, error) {
+	for i := 0; i < len(field); i++ {
+		if field[i] < '0' || field[i] > '9' {
+			return 0, fmt.Errorf("unexpected character %q", field[i])
+		}
+	}
+	return strconv.Atoi(field)
+}
+
+// FEN serializes the board to Forsyth-Edwards Notation.
+func (b *Board) FEN() string {
+	var sb strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			piece := b.squares[rank][file]
+			if piece == -1 {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			sb.WriteByte(pieceFENChars[piece])
+		}
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+		if rank > 0 {
+			sb.WriteByte('/')
+		}
+	}
+
+	if b.turn {
+		sb.WriteString(" b ")
+	} else {
+		sb.WriteString(" w ")
+	}
+
+	if b.castling == 0 {
+		sb.WriteByte('-')
+	} else {
+		for _, side := range []bool{false, true} {
+			for _, kingside := range []bool{true, false} {
+				if b.castling&castlingRight(side, kingside) != 0 {
+					sb.WriteByte(b.castlingNotation(side, kingside))
+				}
+			}
+		}
+	}
+
+	sb.WriteByte(' ')
+	if b.enPassant == nil {
+		sb.WriteByte('-')
+	} else {
+		sb.WriteString(squareNotation(b.enPassant))
+	}
+
+	fmt.Fprintf(&sb, " %d %d", b.halfMoves, b.fullMoves)
+	return sb.String()
+}
+
+func parseSquare(s string) (Position, error) {
+	if len(s) != 2 {
+		return nil, fmt.Errorf("square %q must be a file and a rank", s)
+	}
+	if s[0] < 'a' || s[0] > 'h' {
+		return nil, fmt.Errorf("unexpected file %q in square %q", s[0], s)
+	}
+	if s[1] < '1' || s[1] > '8' {
+		return nil, fmt.Errorf("unexpected rank %q in square %q", s[1], s)
+	}
+	return &Pos{int16(s[1] - '1'), int16(s[0] - 'a')}, nil
+}
+
+func squareNotation(pos Position) string {
+	return string([]byte{byte('a' + pos.GetFile()), byte('1' + pos.GetRank())})
+}
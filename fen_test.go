@@ -0,0 +1,80 @@
+package chessEngine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		START_FEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 b - - 12 57",
+		"4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+	}
+	for _, fen := range fens {
+		var board Board
+		if err := board.LoadFEN(fen); err != nil {
+			t.Errorf("LoadFEN(%q): %v", fen, err)
+			continue
+		}
+		if got := board.FEN(); got != fen {
+			t.Errorf("LoadFEN(%q).FEN() = %q", fen, got)
+		}
+	}
+
+	// the move counters may be left out
+	var board Board
+	if err := board.LoadFEN("4k3/8/8/8/8/8/8/4K3 b - -"); err != nil {
+		t.Fatal(err)
+	}
+	if got := board.FEN(); got != "4k3/8/8/8/8/8/8/4K3 b - - 0 1" {
+		t.Errorf("counters default to %q", got)
+	}
+}
+
+func TestLoadFENErrors(t *testing.T) {
+	tests := []struct {
+		name, fen, err string
+	}{
+		{"empty", "", "fields"},
+		{"too few fields", "4k3/8/8/8/8/8/8/4K3 w -", "fields"},
+		{"too many fields", "4k3/8/8/8/8/8/8/4K3 w - - 0 1 1", "fields"},
+		{"too few ranks", "4k3/8/8/8/8/8/4K3 w - - 0 1", "ranks"},
+		{"too many ranks", "4k3/8/8/8/8/8/8/8/4K3 w - - 0 1", "ranks"},
+		{"long rank", "4k3/54/8/8/8/8/8/4K3 w - - 0 1", "squares"},
+		{"long rank of pieces", "4k3/pppppppppp/8/8/8/8/8/4K3 w - - 0 1", "squares"},
+		{"short rank", "4k3/7/8/8/8/8/8/4K3 w - - 0 1", "squares"},
+		{"unknown piece", "4k3/8/8/8/8/8/8/4X3 w - - 0 1", "character"},
+		{"pawn on last rank", "P3k3/8/8/8/8/8/8/4K3 w - - 0 1", "pawn"},
+		{"no white king", "4k3/8/8/8/8/8/8/8 w - - 0 1", "white king"},
+		{"no black king", "8/8/8/8/8/8/8/4K3 w - - 0 1", "black king"},
+		{"two kings", "4k3/8/8/8/8/8/8/3KK3 w - - 0 1", "white king"},
+		{"bad side", "4k3/8/8/8/8/8/8/4K3 x - - 0 1", "side to move"},
+		{"bad castling character", "r3k2r/8/8/8/8/8/8/R3K2R w KQkx - 0 1", "castling"},
+		{"castling without rook", "4k3/8/8/8/8/8/8/4K3 w K - 0 1", "castling"},
+		{"duplicate castling", "r3k2r/8/8/8/8/8/8/R3K2R w KK - 0 1", "castling"},
+		{"bad en passant square", "4k3/8/8/8/8/8/8/4K3 w - e9 0 1", "en passant"},
+		{"en passant on wrong rank", "4k3/8/8/8/8/8/8/4K3 w - e3 0 1", "en passant"},
+		{"bad halfmove clock", "4k3/8/8/8/8/8/8/4K3 w - - x 1", "halfmove"},
+		{"negative halfmove clock", "4k3/8/8/8/8/8/8/4K3 w - - -1 1", "halfmove"},
+		{"zero fullmove number", "4k3/8/8/8/8/8/8/4K3 w - - 0 0", "fullmove"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var board Board
+			board.InitializeBoard(false)
+			err := board.LoadFEN(test.fen)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("LoadFEN(%q) = %v, expected an error about %s", test.fen, err, test.err)
+			}
+			if board.FEN() != START_FEN {
+				t.Errorf("board changed to %q", board.FEN())
+			}
+		})
+	}
+	if _, err := NewNoobEngineFromFEN("8/8/8/8/8/8/8/8 w - - 0 1"); err == nil {
+		t.Error("engine made from a position without kings")
+	}
+}
@@ -0,0 +1,89 @@
+package chessEngine
+
+import "testing"
+
+// checkHash walks the tree depth plies deep, checking at every node that the
+// hash kept up by Push and Pop is the one worked out from scratch.
+func checkHash(t *testing.T, b *Board, depth int) {
+	if got, want := b.Hash(), b.computeHash(); got != want {
+		t.Fatalf("hash %x after %v, expected %x", got, b.Moves(), want)
+	}
+	if depth == 0 {
+		return
+	}
+	for _, move := range b.GenerateMoves(b.turn) {
+		before := b.Hash()
+		b.Push(move)
+		checkHash(t, b, depth-1)
+		b.Pop()
+		if b.Hash() != before {
+			t.Fatalf("hash changed by %s and back", move)
+		}
+	}
+}
+
+func TestIncrementalHash(t *testing.T) {
+	for _, tc := range perftPositions {
+		t.Run(tc.name, func(t *testing.T) {
+			var board Board
+			if err := board.LoadFEN(tc.fen); err != nil {
+				t.Fatal(err)
+			}
+			checkHash(t, &board, 3)
+		})
+	}
+}
+
+func TestHashIdentifiesPositions(t *testing.T) {
+	hash := func(fen string, moves ...string) uint64 {
+		var board Board
+		if err := board.LoadFEN(fen); err != nil {
+			t.Fatal(err)
+		}
+		for _, notation := range moves {
+			move, err := ParseUCIMove(&board, notation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			board.Push(move)
+		}
+		return board.Hash()
+	}
+
+	if hash(START_FEN, "g1f3", "g8f6", "b1c3") != hash(START_FEN, "b1c3", "g8f6", "g1f3") {
+		t.Error("transposition hashed differently")
+	}
+	if hash(START_FEN, "g1f3", "g8f6", "f3g1", "f6g8") != hash(START_FEN) {
+		t.Error("knights back home hashed differently from the start")
+	}
+	if hash(START_FEN) == hash("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1") {
+		t.Error("side to move not hashed")
+	}
+	if hash("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1") == hash("r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1") {
+		t.Error("castling rights not hashed")
+	}
+	if hash("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1") == hash("4k3/8/8/3pP3/8/8/8/4K3 w - - 0 1") {
+		t.Error("en passant file not hashed")
+	}
+	// the keys come from ZOBRIST_SEED, so hashes saved by one run hold in
+	// the next
+	if got := hash(START_FEN); got != 0x1953fa0620799e0e {
+		t.Errorf("start position hashed to %#x, expected 0x1953fa0620799e0e", got)
+	}
+}
+
+func TestNullMove(t *testing.T) {
+	var board Board
+	if err := board.LoadFEN("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	fen, hash := board.FEN(), board.Hash()
+	board.pushNull()
+	if !board.turn || board.enPassant != nil || board.Hash() != board.computeHash() {
+		t.Errorf("null move left %s with hash %x", board.FEN(), board.Hash())
+	}
+	board.popNull()
+	if board.FEN() != fen || board.Hash() != hash {
+		t.Errorf("null move taken back to %s", board.FEN())
+	}
+}
@@ -0,0 +1,181 @@
+package chessEngine
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// magic maps every blocker arrangement on a slider's relevant squares to an
+// index into its attack table: ((occupied & mask) * number) >> shift.
+type magic struct {
+	mask    Bitboard
+	number  uint64
+	shift   uint
+	attacks []Bitboard
+}
+
+func (m *magic) index(occupied Bitboard) uint64 {
+	return (uint64(occupied&m.mask) * m.number) >> m.shift
+}
+
+var (
+	rookMagics   [64]magic
+	bishopMagics [64]magic
+)
+
+// Magic numbers found by the random search in findMagic, embedded so that
+// start up doesn't have to search for them again.
+var ROOK_MAGIC_NUMBERS = [64]uint64{
+	0x008001508B204001, 0x82C0004020003002, 0x0680100081082000, 0x8180100180080004,
+	0x2200040810020020, 0x0200010804900200, 0x0C00010090024408, 0x0100004020810002,
+	0x0000800080400020, 0x0042002082004104, 0x2884801000812000, 0x8009001001010820,
+	0x0400800400800802, 0x0805000204010008, 0x0002008401020008, 0x2820800849000080,
+	0x8080208000804000, 0x8110084020004000, 0x0402828010002000, 0x0213010010000820,
+	0x5020110008010004, 0x8000808004000201, 0x0008040002904108, 0x000202003100804C,
+	0x5400400080008024, 0x0020002080400084, 0x0000200880100080, 0x4080100080800800,
+	0x0009001002208040, 0x48C2000200100804, 0x0241000100020004, 0x0201008200006401,
+	0xC490204000800085, 0x0800402000401000, 0x1000150041002000, 0x0010010211002008,
+	0x5281001185000800, 0x2012000400808002, 0x00A0800200800100, 0x0060111A82001044,
+	0x0000800040018024, 0x0010004020004004, 0x0400200041010010, 0x0000100021010008,
+	0x2002002008120005, 0x10A0020004008080, 0x0000100801040002, 0x0005110080420004,
+	0x4350208000400880, 0x0610910040002300, 0x3004130840200100, 0x0006002490408A00,
+	0x0420800400080280, 0x0002001018040E00, 0x0816100108224400, 0x0080008041040200,
+	0x0000104504208202, 0x8200850010204602, 0x100100C409506001, 0x0010850008A01001,
+	0x0242000884211002, 0x0209000400020801, 0x0218080082100104, 0x208400408C002902,
+}
+
+var BISHOP_MAGIC_NUMBERS = [64]uint64{
+	0x40080200EA020200, 0x6A02085104088200, 0x210840810200A442, 0x4012408300000000,
+	0xC014102800A10000, 0x420A300420200030, 0x8004012482600008, 0x0010808098014000,
+	0x180008081040CA08, 0x0058444114410600, 0x000218026D221002, 0x1010040404880004,
+	0x4080040422000003, 0xA220021202208042, 0x0080140404030880, 0x090042942C060904,
+	0x01C8011011211802, 0x1108089001852C00, 0x00040118024A1200, 0x000801092040C010,
+	0x0404044A00A20004, 0x0400200410041000, 0x1021018041082008, 0x0022400200460881,
+	0x0010401008820405, 0x2048200003141100, 0x8240280050004148, 0x0220080089081020,
+	0x0090840100802008, 0x0008020008208405, 0x0024006001011008, 0x0005010002007104,
+	0x0030105004050400, 0x02109820104C048C, 0x2404020200010404, 0x0020040402080210,
+	0x1788202400024100, 0xA008204500089000, 0x242A82920001080E, 0x0406048128420200,
+	0x0C01011110404018, 0xC200480290008800, 0x2000812488004040, 0x2000202128000400,
+	0x0C90080100492402, 0x0004100082041108, 0x4224084A00420404, 0x000401020A020420,
+	0x100080B808408424, 0x0221092801041461, 0x0006020201048001, 0x120838402A080100,
+	0x0000005022120001, 0x0090220202120040, 0x01042008020CA006, 0x00A8520800410000,
+	0x0140440888080201, 0x00400C8045182004, 0x0000408100809000, 0x0161C90200842408,
+	0x2042000008430402, 0x0800022202022201, 0x114008208C0C4045, 0x1008200090820082,
+}
+
+const MAGIC_SEED = 0x5eed
+
+func init() {
+	for sq := 0; sq < 64; sq++ {
+		rookMagics[sq] = findMagic(sq, rookMask(sq), slowRookAttacks, ROOK_MAGIC_NUMBERS[sq])
+		bishopMagics[sq] = findMagic(sq, bishopMask(sq), slowBishopAttacks, BISHOP_MAGIC_NUMBERS[sq])
+	}
+}
+
+func rookAttacks(sq int, occupied Bitboard) Bitboard {
+	m := &rookMagics[sq]
+	return m.attacks[m.index(occupied)]
+}
+
+func bishopAttacks(sq int, occupied Bitboard) Bitboard {
+	m := &bishopMagics[sq]
+	return m.attacks[m.index(occupied)]
+}
+
+const (
+	RANK_1 Bitboard = 0xFF
+	RANK_8 Bitboard = 0xFF << 56
+	FILE_A Bitboard = 0x0101010101010101
+	FILE_H Bitboard = FILE_A << 7
+)
+
+// rookMask is the set of squares whose occupancy matters for a rook on sq;
+// the last square of each ray is left out since it is attacked either way.
+func rookMask(sq int) Bitboard {
+	return rays[NORTH][sq]&^RANK_8 | rays[SOUTH][sq]&^RANK_1 |
+		rays[EAST][sq]&^FILE_H | rays[WEST][sq]&^FILE_A
+}
+
+func bishopMask(sq int) Bitboard {
+	edges := RANK_1 | RANK_8 | FILE_A | FILE_H
+	return (rays[NORTH_EAST][sq] | rays[NORTH_WEST][sq] | rays[SOUTH_EAST][sq] | rays[SOUTH_WEST][sq]) &^ edges
+}
+
+// findMagic builds the attack table of a slider on sq, trying first and
+// then sparse random numbers until one maps every subset of mask to a slot
+// without a conflicting attack set. The random search only runs if the
+// masks or the embedded numbers change.
+func findMagic(sq int, mask Bitboard, slowAttacks func(int, Bitboard) Bitboard, first uint64) magic {
+	bits := mask.Count()
+	size := 1 << bits
+	occupancies := make([]Bitboard, 0, size)
+	references := make([]Bitboard, 0, size)
+	subset := Bitboard(0)
+	for {
+		occupancies = append(occupancies, subset)
+		references = append(references, slowAttacks(sq, subset))
+		subset = (subset - mask) & mask
+		if subset == 0 {
+			break
+		}
+	}
+
+	m := magic{mask: mask, number: first, shift: uint(64 - bits), attacks: make([]Bitboard, size)}
+	rng := rand.New(rand.NewPCG(MAGIC_SEED, uint64(sq)))
+	// epoch[i] == attempt marks attacks[i] as filled during this attempt
+	epoch := make([]int, size)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			m.number = rng.Uint64() & rng.Uint64() & rng.Uint64()
+			if Bitboard((uint64(mask)*m.number)>>56).Count() < 6 {
+				continue
+			}
+		}
+		ok := true
+		for i, occupied := range occupancies {
+			idx := m.index(occupied)
+			if epoch[idx] != attempt {
+				epoch[idx] = attempt
+				m.attacks[idx] = references[i]
+			} else if m.attacks[idx] != references[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return m
+		}
+	}
+}
+
+// VerifyMagics checks the rook and bishop lookups against the ray walking
+// reference for every square and every arrangement of blockers.
+func VerifyMagics() error {
+	for sq := 0; sq < 64; sq++ {
+		if err := verifyMagic(sq, &rookMagics[sq], slowRookAttacks, "rook"); err != nil {
+			return err
+		}
+		if err := verifyMagic(sq, &bishopMagics[sq], slowBishopAttacks, "bishop"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyMagic(sq int, m *magic, slowAttacks func(int, Bitboard) Bitboard, name string) error {
+	subset := Bitboard(0)
+	for {
+		// squares outside the mask must not change the result
+		for _, noise := range []Bitboard{0, ^m.mask} {
+			occupied := subset | noise
+			if got, want := m.attacks[m.index(occupied)], slowAttacks(sq, occupied); got != want {
+				return fmt.Errorf("%s magic for %s gives %#x for occupancy %#x, expected %#x",
+					name, squareNotation(positionOf(sq)), uint64(got), uint64(occupied), uint64(want))
+			}
+		}
+		subset = (subset - m.mask) & m.mask
+		if subset == 0 {
+			return nil
+		}
+	}
+}
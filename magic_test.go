@@ -0,0 +1,128 @@
+package chessEngine
+
+import "testing"
+
+func TestVerifyMagics(t *testing.T) {
+	if err := VerifyMagics(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoopGenerators(t *testing.T) {
+	var board Board
+	if err := board.LoadFEN(BENCH_FEN); err != nil {
+		t.Fatal(err)
+	}
+	for _, piece := range []Piece{WHITE_ROOK, BLACK_ROOK, WHITE_BISHOP, BLACK_BISHOP} {
+		generate, loops := GenerateRookMoves, generateWithLoops(rookDirections)
+		if piece == WHITE_BISHOP || piece == BLACK_BISHOP {
+			generate, loops = GenerateBishopMoves, generateWithLoops(bishopDirections)
+		}
+		for _, pos := range board.GetPiecePositions(piece) {
+			if got, want := len(loops(&board, pos)), len(generate(&board, pos)); got != want {
+				t.Errorf("%d moves from %s walking the board, expected %d", got, squareNotation(pos), want)
+			}
+		}
+	}
+}
+
+// a middlegame position with sliders that are neither free nor boxed in
+const BENCH_FEN = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+func benchmarkAttacks(b *testing.B, attacks func(int, Bitboard) Bitboard) {
+	var board Board
+	if err := board.LoadFEN(BENCH_FEN); err != nil {
+		b.Fatal(err)
+	}
+	occupied := board.all()
+	var sink Bitboard
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sink ^= attacks(i&63, occupied)
+	}
+	_ = sink
+}
+
+func BenchmarkRookAttacksMagic(b *testing.B)   { benchmarkAttacks(b, rookAttacks) }
+func BenchmarkRookAttacksRays(b *testing.B)    { benchmarkAttacks(b, slowRookAttacks) }
+func BenchmarkBishopAttacksMagic(b *testing.B) { benchmarkAttacks(b, bishopAttacks) }
+func BenchmarkBishopAttacksRays(b *testing.B)  { benchmarkAttacks(b, slowBishopAttacks) }
+
+func benchmarkGenerate(b *testing.B, pieces []Piece, generate func(*Board, Position) []Move) {
+	var board Board
+	if err := board.LoadFEN(BENCH_FEN); err != nil {
+		b.Fatal(err)
+	}
+	var positions []Position
+	for _, piece := range pieces {
+		positions = append(positions, board.GetPiecePositions(piece)...)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, pos := range positions {
+			generate(&board, pos)
+		}
+	}
+}
+
+// generateWithRays builds the same moves as the generators do, but from
+// the ray walking attacks.
+func generateWithRays(attacks func(int, Bitboard) Bitboard) func(*Board, Position) []Move {
+	return func(board *Board, pos Position) []Move {
+		sq, piece := squareIndex(pos), board.Get(pos)
+		return appendMoves(nil, piece, sq, attacks(sq, board.all())&^board.occupied[sideIndex(piece.Color())])
+	}
+}
+
+// generateWithLoops builds the moves the way the generators did before the
+// board had bitboards: walking the squares array one step at a time in
+// every direction until a piece is in the way.
+func generateWithLoops(directions [][2]int16) func(*Board, Position) []Move {
+	return func(board *Board, pos Position) []Move {
+		piece := board.Get(pos)
+		var moves []Move
+		for _, d := range directions {
+			x, y := pos.GetRank()+d[0], pos.GetFile()+d[1]
+			for x >= 0 && x < 8 && y >= 0 && y < 8 {
+				target := board.Get(&Pos{x, y})
+				if target == -1 || target.Color() != piece.Color() {
+					moves = append(moves, Move{piece, pos, &Pos{x, y}, -1, false})
+				}
+				if target != -1 {
+					break
+				}
+				x, y = x+d[0], y+d[1]
+			}
+		}
+		return moves
+	}
+}
+
+var (
+	rookDirections   = [][2]int16{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	bishopDirections = [][2]int16{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+)
+
+func BenchmarkGenerateRookMovesMagic(b *testing.B) {
+	benchmarkGenerate(b, []Piece{WHITE_ROOK, BLACK_ROOK}, GenerateRookMoves)
+}
+
+func BenchmarkGenerateRookMovesRays(b *testing.B) {
+	benchmarkGenerate(b, []Piece{WHITE_ROOK, BLACK_ROOK}, generateWithRays(slowRookAttacks))
+}
+
+func BenchmarkGenerateRookMovesLoops(b *testing.B) {
+	benchmarkGenerate(b, []Piece{WHITE_ROOK, BLACK_ROOK}, generateWithLoops(rookDirections))
+}
+
+func BenchmarkGenerateBishopMovesMagic(b *testing.B) {
+	benchmarkGenerate(b, []Piece{WHITE_BISHOP, BLACK_BISHOP}, GenerateBishopMoves)
+}
+
+func BenchmarkGenerateBishopMovesRays(b *testing.B) {
+	benchmarkGenerate(b, []Piece{WHITE_BISHOP, BLACK_BISHOP}, generateWithRays(slowBishopAttacks))
+}
+
+func BenchmarkGenerateBishopMovesLoops(b *testing.B) {
+	benchmarkGenerate(b, []Piece{WHITE_BISHOP, BLACK_BISHOP}, generateWithLoops(bishopDirections))
+}
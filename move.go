@@ -0,0 +1,151 @@
+package chessEngine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NewMove builds a move of piece from init to final, promotion being -1
+// unless a pawn promotes. Whether it is legal is up to the board it's
+// played on. Castling moves come from GenerateMoves, ParseUCIMove or
+// ParseSAN.
+func NewMove(piece Piece, init Position, final Position, promotion Piece) Move {
+	return Move{piece, init, final, promotion, false}
+}
+
+func (m Move) GetPiece() Piece {
+	return m.piece
+}
+
+// GetInit returns a copy of the square the piece moves from, since moves
+// share their squares with the board's square table.
+func (m Move) GetInit() Position {
+	return copyPosition(m.init)
+}
+
+// GetFinal returns a copy of the square the piece moves to.
+func (m Move) GetFinal() Position {
+	return copyPosition(m.final)
+}
+
+func copyPosition(p Position) Position {
+	if p == nil {
+		return nil
+	}
+	return &Pos{p.GetRank(), p.GetFile()}
+}
+
+// GetPromotion returns the piece a pawn promotes to, or -1.
+func (m Move) GetPromotion() Piece {
+	return m.promotion
+}
+
+// IsCastling reports whether the king castles, GetFinal then being the
+// square it lands on.
+func (m Move) IsCastling() bool {
+	return m.castling
+}
+
+// String writes the move in UCI long algebraic notation, e.g. "e2e4" or
+// "e7e8q", and the zero Move as the null move "0000".
+func (m Move) String() string {
+	if m.init == nil || m.final == nil {
+		return "0000"
+	}
+	return NotationFromMove(m)
+}
+
+// sameMove tells whether a and b are the same move, the zero Move only
+// being the same as itself.
+func sameMove(a, b Move) bool {
+	if a.init == nil || b.init == nil {
+		return a.init == nil && b.init == nil
+	}
+	return a.piece == b.piece && a.promotion == b.promotion && a.castling == b.castling &&
+		squareIndex(a.init) == squareIndex(b.init) && squareIndex(a.final) == squareIndex(b.final)
+}
+
+// ParseUCIMove reads a move in UCI long algebraic notation such as "e2e4"
+// or "e7e8q" and returns it if it is legal on board. Chess960 boards take
+// castling as the king moving onto its rook, see MoveNotation.
+func ParseUCIMove(board *Board, s string) (Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, fmt.Errorf("invalid UCI move %q: expected 4 or 5 characters", s)
+	}
+	for _, square := range []string{s[0:2], s[2:4]} {
+		if _, err := parseSquare(square); err != nil {
+			return Move{}, fmt.Errorf("invalid UCI move %q: %s", s, err.Error())
+		}
+	}
+	if len(s) == 5 && strings.IndexByte("qrbn", s[4]) < 0 {
+		return Move{}, fmt.Errorf("invalid UCI move %q: unexpected promotion %q", s, s[4])
+	}
+	return board.moveFromCoordinates(s)
+}
+
+// moveJSON is how a Move crosses process boundaries: the piece by its FEN
+// letter and the squares by name, e.g.
+// {"piece":"P","from":"e7","to":"e8","promotion":"Q"}.
+type moveJSON struct {
+	Piece     string `json:"piece"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Promotion string `json:"promotion,omitempty"`
+	Castling  bool   `json:"castling,omitempty"`
+}
+
+func (m Move) MarshalJSON() ([]byte, error) {
+	if m.init == nil || m.final == nil {
+		return nil, fmt.Errorf("can't marshal the null move")
+	}
+	data := moveJSON{
+		Piece:    string(pieceFENChars[m.piece]),
+		From:     squareNotation(m.init),
+		To:       squareNotation(m.final),
+		Castling: m.castling,
+	}
+	if m.promotion != -1 {
+		data.Promotion = string(pieceFENChars[m.promotion])
+	}
+	return json.Marshal(data)
+}
+
+func (m *Move) UnmarshalJSON(b []byte) error {
+	var data moveJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	piece, err := pieceFromFENLetter(data.Piece)
+	if err != nil {
+		return fmt.Errorf("invalid move piece: %s", err.Error())
+	}
+	init, err := parseSquare(data.From)
+	if err != nil {
+		return fmt.Errorf("invalid move origin: %s", err.Error())
+	}
+	final, err := parseSquare(data.To)
+	if err != nil {
+		return fmt.Errorf("invalid move destination: %s", err.Error())
+	}
+	promotion := Piece(-1)
+	if data.Promotion != "" {
+		if promotion, err = pieceFromFENLetter(data.Promotion); err != nil {
+			return fmt.Errorf("invalid move promotion: %s", err.Error())
+		}
+	}
+	if data.Castling && !piece.IsKing() {
+		return fmt.Errorf("invalid move: only the king castles")
+	}
+	*m = Move{piece, init, final, promotion, data.Castling}
+	return nil
+}
+
+func pieceFromFENLetter(s string) (Piece, error) {
+	if len(s) == 1 {
+		if piece, ok := fenPieces[s[0]]; ok {
+			return piece, nil
+		}
+	}
+	return -1, fmt.Errorf("unexpected piece %q", s)
+}
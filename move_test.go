@@ -0,0 +1,72 @@
+package chessEngine
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseUCIMove(t *testing.T) {
+	var board Board
+	if err := board.LoadFEN("3k4/4P3/8/8/8/8/8/4K3 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"e1d2", "e7e8q", "e7e8n"} {
+		move, err := ParseUCIMove(&board, s)
+		if err != nil {
+			t.Errorf("ParseUCIMove(%q): %v", s, err)
+		} else if move.String() != s {
+			t.Errorf("ParseUCIMove(%q).String() = %q", s, move.String())
+		}
+	}
+	for _, s := range []string{"", "e1", "e1e3", "e7e8", "e7e8k", "e1d2q", "i1d2", "e7e8qq"} {
+		if move, err := ParseUCIMove(&board, s); err == nil {
+			t.Errorf("ParseUCIMove(%q) = %s, expected an error", s, move)
+		}
+	}
+	if s := (Move{}).String(); s != "0000" {
+		t.Errorf("zero Move prints as %q, expected \"0000\"", s)
+	}
+}
+
+func TestMoveAccessorsCopy(t *testing.T) {
+	var board Board
+	board.InitializeBoard(false)
+	move, err := ParseUCIMove(&board, "e2e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	init, final := move.GetInit().(*Pos), move.GetFinal().(*Pos)
+	init.X, final.Y = 5, 0
+	if move.String() != "e2e4" || positionOf(12).GetRank() != 1 || positionOf(28).GetFile() != 4 {
+		t.Errorf("writing through the accessors changed %s and the square table", move)
+	}
+	if (Move{}).GetInit() != nil {
+		t.Error("zero Move has a square")
+	}
+}
+
+func TestMoveJSON(t *testing.T) {
+	moves := []Move{
+		NewMove(WHITE_PAWN, &Pos{1, 4}, &Pos{3, 4}, -1),
+		NewMove(BLACK_PAWN, &Pos{1, 3}, &Pos{0, 2}, BLACK_KNIGHT),
+	}
+	for _, move := range moves {
+		data, err := json.Marshal(move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Move
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if decoded.GetPiece() != move.GetPiece() || decoded.String() != move.String() {
+			t.Errorf("%s read back as %s", data, decoded)
+		}
+	}
+	var move Move
+	for _, data := range []string{`{"piece":"X","from":"e2","to":"e4"}`, `{"piece":"P","from":"e9","to":"e4"}`, `"e2e4"`} {
+		if err := json.Unmarshal([]byte(data), &move); err == nil {
+			t.Errorf("%s unmarshalled without error", data)
+		}
+	}
+}
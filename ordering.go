@@ -0,0 +1,95 @@
+package chessEngine
+
+import "slices"
+
+// Move ordering scores, higher being searched first: the hash move, then
+// captures and promotions by MVV-LVA, then the killer moves, then the other
+// quiet moves by their history.
+const (
+	HASH_MOVE_SCORE = 1 << 30
+	CAPTURE_SCORE   = 1 << 24
+	KILLER_SCORE    = 1 << 22
+	// history scores are halved when one of them gets this high, so that
+	// they never reach the killers and recent cutoffs count the most
+	MAX_HISTORY = 1 << 20
+)
+
+// mvvLva scores a capture by the most valuable victim first and, among
+// captures of the same piece, the least valuable attacker first. A
+// promotion counts as capturing the piece promoted to.
+func (b *Board) mvvLva(move Move) int {
+	victim := 0
+	if captured := b.Get(move.final); captured != -1 && !move.castling {
+		victim = PIECE_VALUE[captured]
+	} else if b.isCapture(move) {
+		victim = PIECE_VALUE[WHITE_PAWN]
+	}
+	if move.promotion != -1 {
+		victim += PIECE_VALUE[move.promotion]
+	}
+	return victim*16 - PIECE_VALUE[move.piece]
+}
+
+// isTactical tells whether move changes the material, which the killers and
+// history leave to MVV-LVA.
+func (b *Board) isTactical(move Move) bool {
+	return move.promotion != -1 || (!move.castling && b.isCapture(move))
+}
+
+// scoreMove rates move, played at ply plies from the root, for ordering.
+func (c *searchContext) scoreMove(b *Board, move Move, hashMove Move, ply int) int {
+	switch {
+	case sameMove(move, hashMove):
+		return HASH_MOVE_SCORE
+	case b.isTactical(move):
+		return CAPTURE_SCORE + b.mvvLva(move)
+	case sameMove(move, c.killers[ply][0]):
+		return KILLER_SCORE + 1
+	case sameMove(move, c.killers[ply][1]):
+		return KILLER_SCORE
+	}
+	return int(c.history[move.piece][squareIndex(move.final)])
+}
+
+// orderMoves sorts the children, best first, so that alpha-beta finds the
+// cutoffs early. An insertion sort does for the few dozen moves there are.
+func (n *Node) orderMoves(hashMove Move) {
+	scores := make([]int, len(n.children))
+	for i, child := range n.children {
+		scores[i] = n.search.scoreMove(n.board, child.Move, hashMove, n.ply)
+	}
+	for i := 1; i < len(n.children); i++ {
+		for j := i; j > 0 && scores[j] > scores[j-1]; j-- {
+			scores[j], scores[j-1] = scores[j-1], scores[j]
+			n.children[j], n.children[j-1] = n.children[j-1], n.children[j]
+		}
+	}
+}
+
+// cutoff records that the quiet move made the node at ply fail high when
+// searched depth plies deep, making it a killer and raising its history.
+func (c *searchContext) cutoff(b *Board, move Move, ply, depth int) {
+	if b.isTactical(move) {
+		return
+	}
+	if !sameMove(move, c.killers[ply][0]) {
+		c.killers[ply][1] = c.killers[ply][0]
+		c.killers[ply][0] = move
+	}
+	history := &c.history[move.piece][squareIndex(move.final)]
+	*history += int32(depth * depth)
+	if *history >= MAX_HISTORY {
+		for piece := range c.history {
+			for sq := range c.history[piece] {
+				c.history[piece][sq] /= 2
+			}
+		}
+	}
+}
+
+// orderCaptures sorts the moves of the quiescence search by MVV-LVA.
+func (b *Board) orderCaptures(moves []Move) {
+	slices.SortStableFunc(moves, func(x, y Move) int {
+		return b.mvvLva(y) - b.mvvLva(x)
+	})
+}
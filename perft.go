@@ -0,0 +1,35 @@
+package chessEngine
+
+// Perft counts the leaf nodes of the tree of legal moves depth plies deep,
+// the standard way of checking a move generator against known numbers.
+func (b *Board) Perft(depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	moves := b.GenerateMoves(b.turn)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var nodes uint64
+	for _, move := range moves {
+		b.Push(move)
+		nodes += b.Perft(depth - 1)
+		b.Pop()
+	}
+	return nodes
+}
+
+// Divide runs Perft below every legal move, keyed by the move in coordinate
+// notation, to narrow a wrong count down to the move that causes it.
+func (b *Board) Divide(depth int) map[string]uint64 {
+	res := make(map[string]uint64)
+	if depth < 1 {
+		return res
+	}
+	for _, move := range b.GenerateMoves(b.turn) {
+		b.Push(move)
+		res[b.MoveNotation(move)] = b.Perft(depth - 1)
+		b.Pop()
+	}
+	return res
+}
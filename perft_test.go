@@ -0,0 +1,65 @@
+package chessEngine
+
+import "testing"
+
+// Reference counts from https://www.chessprogramming.org/Perft_Results and
+// https://www.chessprogramming.org/Chess960_Perft_Results
+var perftPositions = []struct {
+	name   string
+	fen    string
+	counts []uint64
+	// counts from this depth on are only checked without -short
+	long int
+}{
+	{"start", START_FEN, []uint64{20, 400, 8902, 197281, 4865609}, 4},
+	{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []uint64{48, 2039, 97862, 4085603}, 3},
+	{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []uint64{14, 191, 2812, 43238, 674624}, 5},
+	{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []uint64{6, 264, 9467, 422333}, 4},
+	{"position 4 mirrored", "r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1", []uint64{6, 264, 9467, 422333}, 4},
+	{"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []uint64{44, 1486, 62379, 2103487}, 3},
+	{"position 6", "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", []uint64{46, 2079, 89890, 3894594}, 3},
+	{"chess960 1", "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []uint64{21, 528, 12189, 326672}, 4},
+	{"chess960 2", "2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9", []uint64{21, 807, 18002, 667366}, 4},
+	{"chess960 3", "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9", []uint64{20, 479, 10471, 273318}, 4},
+	{"chess960 4", "qbbnnrkr/2pp2pp/p7/1p2pp2/8/P3PP2/1PPP1KPP/QBBNNR1R w hf - 0 9", []uint64{22, 593, 13440, 382958}, 4},
+	{"chess960 5", "1nbbnrkr/p1p1ppp1/3p4/1p3P1p/3Pq2P/8/PPP1P1P1/QNBBNRKR w HFhf - 0 9", []uint64{28, 1120, 31058, 1171749}, 4},
+	{"chess960 6", "qnbnr1kr/ppp1b1pp/4p3/3p1p2/8/2NPP3/PPP1BPPP/QNB1R1KR w HEhe - 1 9", []uint64{29, 899, 26578, 824055}, 4},
+}
+
+func TestPerft(t *testing.T) {
+	for _, tc := range perftPositions {
+		t.Run(tc.name, func(t *testing.T) {
+			var board Board
+			if err := board.LoadFEN(tc.fen); err != nil {
+				t.Fatal(err)
+			}
+			fen := board.FEN()
+			for i, want := range tc.counts {
+				depth := i + 1
+				if testing.Short() && depth >= tc.long {
+					break
+				}
+				if got := board.Perft(depth); got != want {
+					t.Errorf("perft(%d) = %d, expected %d", depth, got, want)
+				}
+			}
+			if after := board.FEN(); after != fen {
+				t.Errorf("board changed by perft: %s", after)
+			}
+		})
+	}
+}
+
+func TestDivideAddsUpToPerft(t *testing.T) {
+	var board Board
+	if err := board.LoadFEN(perftPositions[1].fen); err != nil {
+		t.Fatal(err)
+	}
+	var total uint64
+	for _, nodes := range board.Divide(2) {
+		total += nodes
+	}
+	if want := perftPositions[1].counts[1]; total != want {
+		t.Errorf("divide(2) adds up to %d, expected %d", total, want)
+	}
+}
@@ -0,0 +1,129 @@
+// Package pgn reads and writes chess games in Portable Game Notation.
+package pgn
+
+import (
+	"fmt"
+	"time"
+
+	chessEngine "github.com/kishanshukla-2307/chess-engine"
+)
+
+// SEVEN_TAG_ROSTER lists the tags every PGN game has, in the order they are
+// written.
+var SEVEN_TAG_ROSTER = [7]string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// Tag is a PGN tag pair such as [White "Morphy, Paul"].
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Node is one move of a game with its annotations. Each variation is a line
+// played instead of this move, from the position before it.
+type Node struct {
+	Move chessEngine.Move
+	SAN  string
+	// numeric annotation glyphs, $1 for "!", $2 for "?" and so on
+	NAGs []int
+	// CommentBefore only appears before the first move of a line
+	CommentBefore string
+	Comment       string
+	Variations    [][]*Node
+}
+
+type Game struct {
+	Tags  []Tag
+	Moves []*Node
+	// Comment is the movetext of a game without moves but a comment, the
+	// comments of the others belong to their moves
+	Comment string
+	Result  chessEngine.GameResult
+}
+
+// Tag returns the value of the tag name, or "" if the game doesn't have it.
+func (g *Game) Tag(name string) string {
+	for _, tag := range g.Tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+// SetTag replaces the value of the tag name or adds it.
+func (g *Game) SetTag(name, value string) {
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, Tag{name, value})
+}
+
+// StartBoard returns the position the game starts from: the FEN tag if
+// there is one, the standard starting position otherwise.
+func (g *Game) StartBoard() (*chessEngine.Board, error) {
+	var board chessEngine.Board
+	fen := g.Tag("FEN")
+	if fen == "" {
+		fen = chessEngine.START_FEN
+	}
+	if err := board.LoadFEN(fen); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// Board returns the position at the end of the main line.
+func (g *Game) Board() (*chessEngine.Board, error) {
+	board, err := g.StartBoard()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range g.Moves {
+		board.Push(node.Move)
+	}
+	return board, nil
+}
+
+// FromEngine records the game played on the engine's board so far, with
+// the Seven Tag Roster filled in for an engine game. With evals each move
+// Run played gets its search score as a {[%eval ...]} comment.
+func FromEngine(ne *chessEngine.NoobEngine, evals bool) *Game {
+	board := ne.Board()
+	start := board.Clone()
+	for {
+		if _, err := start.Pop(); err != nil {
+			break
+		}
+	}
+	result, _ := board.Status()
+
+	game := &Game{Result: result}
+	game.SetTag("Event", "NoobEngine game")
+	game.SetTag("Site", "?")
+	game.SetTag("Date", time.Now().Format("2006.01.02"))
+	game.SetTag("Round", "-")
+	game.SetTag("White", "NoobEngine")
+	game.SetTag("Black", "NoobEngine")
+	game.SetTag("Result", result.String())
+	if start.Chess960() {
+		game.SetTag("Variant", "Chess960")
+	}
+	if fen := start.FEN(); fen != chessEngine.START_FEN || start.Chess960() {
+		game.SetTag("SetUp", "1")
+		game.SetTag("FEN", fen)
+	}
+
+	scores := ne.Evals()
+	for i, move := range board.Moves() {
+		node := &Node{Move: move, SAN: start.SAN(move)}
+		if score, ok := scores[i]; evals && ok {
+			node.Comment = fmt.Sprintf("[%%eval %s]", score)
+		}
+		game.Moves = append(game.Moves, node)
+		start.Push(move)
+	}
+	return game
+}
@@ -0,0 +1,192 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+
+	chessEngine "github.com/kishanshukla-2307/chess-engine"
+)
+
+const TWO_GAMES = `[Event "Casual game"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[Round "?"]
+[White "Morphy, Paul"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 {This is a weak move already.} 4. dxe5 Bxf3 5.
+Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7 8. Nc3 c6 9. Bg5 b5 10. Nxb5! cxb5 11. Bxb5+
+Nbd7 12. O-O-O Rd8 13. Rxd7 Rxd7 14. Rd1 Qe6 15. Bxd7+ Nxd7 16. Qb8+ Nxb8 17.
+Rd8# 1-0
+
+% an escaped line
+[Event "?"]
+[Result "*"]
+[SetUp "1"]
+[FEN "4k3/4P3/8/8/8/8/8/R3K2R w KQ - 0 1"]
+
+{Start} 1. O-O $1 (1. Ra8+ $2 {Check} (1. Kd2 Kxe7) 1... Kxe7 2. Ra7+) (1. Rh8+!? Kxe7)
+1... Kxe7 ; the king takes
+2. Ra7+ *
+`
+
+func TestReadAll(t *testing.T) {
+	games, err := ReadAll(strings.NewReader(TWO_GAMES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("read %d games, expected 2", len(games))
+	}
+
+	morphy := games[0]
+	if morphy.Tag("White") != "Morphy, Paul" || morphy.Result != chessEngine.WHITE_WINS {
+		t.Errorf("unexpected tags %v and result %s", morphy.Tags, morphy.Result)
+	}
+	if len(morphy.Moves) != 33 {
+		t.Errorf("read %d moves, expected 33", len(morphy.Moves))
+	}
+	if comment := morphy.Moves[5].Comment; comment != "This is a weak move already." {
+		t.Errorf("unexpected comment %q", comment)
+	}
+	if nags := morphy.Moves[18].NAGs; len(nags) != 1 || nags[0] != 1 {
+		t.Errorf("Nxb5! has annotations %v, expected [1]", nags)
+	}
+	board, err := morphy.Board()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, termination := board.Status(); result != chessEngine.WHITE_WINS || termination != chessEngine.CHECKMATE {
+		t.Errorf("final position is %s (%s), expected mate", result, termination)
+	}
+
+	study := games[1]
+	if study.Result != chessEngine.ONGOING || len(study.Moves) != 3 {
+		t.Fatalf("unexpected result %s or %d moves", study.Result, len(study.Moves))
+	}
+	castle := study.Moves[0]
+	if castle.CommentBefore != "Start" || castle.SAN != "O-O" || len(castle.Variations) != 2 {
+		t.Fatalf("unexpected first move %+v", castle)
+	}
+	check := castle.Variations[0]
+	if len(check) != 3 || check[0].Comment != "Check" || len(check[0].Variations) != 1 || len(check[0].Variations[0]) != 2 {
+		t.Errorf("unexpected nested variation %+v", check)
+	}
+	if alternative := castle.Variations[1][0]; alternative.SAN != "Rh8+" || alternative.NAGs[0] != 5 {
+		t.Errorf("unexpected second variation %+v", alternative)
+	}
+	if comment := study.Moves[1].Comment; comment != "" {
+		t.Errorf("line comment read as %q", comment)
+	}
+}
+
+func TestWriteReadsBack(t *testing.T) {
+	games, err := ReadAll(strings.NewReader(TWO_GAMES))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for _, game := range games {
+		if err := Write(&sb, game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if len(line) > MAX_LINE_LENGTH {
+			t.Errorf("line longer than %d characters: %q", MAX_LINE_LENGTH, line)
+		}
+	}
+	again, err := ReadAll(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, sb.String())
+	}
+	for i := range games {
+		if again[i].String() != games[i].String() {
+			t.Errorf("game %d changed when read back:\n%s\n%s", i, games[i], again[i])
+		}
+	}
+	if !strings.Contains(games[1].String(), "1. O-O $1 (1. Ra8+ $2 {Check} (1. Kd2 Kxe7) 1... Kxe7 2. Ra7+)") {
+		t.Errorf("unexpected movetext:\n%s", games[1])
+	}
+}
+
+func TestWriteSetUp(t *testing.T) {
+	game := &Game{}
+	game.SetTag("FEN", "4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	if text := game.String(); !strings.Contains(text, "[SetUp \"1\"]\n[FEN ") {
+		t.Errorf("FEN written without SetUp:\n%s", text)
+	}
+	game.SetTag("SetUp", "0")
+	if text := game.String(); strings.Count(text, "[SetUp ") != 1 || !strings.Contains(text, "[SetUp \"1\"]") {
+		t.Errorf("SetUp 0 written with a FEN:\n%s", text)
+	}
+}
+
+func TestCommentWithoutMoves(t *testing.T) {
+	games, err := ReadAll(strings.NewReader("{only a comment} *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 || games[0].Comment != "only a comment" || len(games[0].Moves) != 0 {
+		t.Fatalf("unexpected games %+v", games)
+	}
+	if text := games[0].String(); !strings.HasSuffix(text, "\n{only a comment} *\n") {
+		t.Errorf("comment not written back:\n%s", text)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, text := range []string{
+		"1. e4 e6 2. Ke3 *",
+		"1. e4 (1. d4 *",
+		"1. e4 ) *",
+		`[Event "unterminated]`,
+		"{no end",
+		"$1 e4 *",
+		"1. e4 (1... e5) *",
+	} {
+		if _, err := ReadAll(strings.NewReader(text)); err == nil {
+			t.Errorf("%q read without error", text)
+		}
+	}
+}
+
+func TestFromEngine(t *testing.T) {
+	ne, err := chessEngine.NewNoobEngine(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := ne.Board()
+	for _, san := range []string{"f3", "e5", "g4", "Qh4#"} {
+		move, err := board.ParseSAN(san)
+		if err != nil {
+			t.Fatal(err)
+		}
+		board.Push(move)
+	}
+	text := FromEngine(ne, true).String()
+	for _, want := range []string{`[White "NoobEngine"]`, `[Result "0-1"]`, "1. f3 e5 2. g4 Qh4# 0-1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("%q missing from\n%s", want, text)
+		}
+	}
+
+	// only the move Run played has an eval
+	ne, err = chessEngine.NewNoobEngine(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, san := range []string{"f3", "e5", "g4"} {
+		if err := ne.MakeMoveByNotation(san); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ne.Run(); err != nil {
+		t.Fatal(err)
+	}
+	text = FromEngine(ne, true).String()
+	if want := "1. f3 e5 2. g4 Qh4# {[%eval #-1]} 0-1"; !strings.Contains(text, want) {
+		t.Errorf("%q missing from\n%s", want, text)
+	}
+}
@@ -0,0 +1,386 @@
+package pgn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	chessEngine "github.com/kishanshukla-2307/chess-engine"
+)
+
+type tokenKind int
+
+const (
+	TOKEN_EOF       tokenKind = 0
+	TOKEN_SYMBOL    tokenKind = 1
+	TOKEN_STRING    tokenKind = 2
+	TOKEN_COMMENT   tokenKind = 3
+	TOKEN_NAG       tokenKind = 4
+	TOKEN_TAG_OPEN  tokenKind = 5
+	TOKEN_TAG_CLOSE tokenKind = 6
+	TOKEN_VAR_OPEN  tokenKind = 7
+	TOKEN_VAR_CLOSE tokenKind = 8
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// move suffix annotations and the glyphs they stand for
+var suffixNAGs = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+var results = map[string]chessEngine.GameResult{
+	"1-0": chessEngine.WHITE_WINS, "0-1": chessEngine.BLACK_WINS, "1/2-1/2": chessEngine.DRAW, "*": chessEngine.ONGOING,
+}
+
+// Reader reads the games of a PGN file one at a time.
+type Reader struct {
+	r         *bufio.Reader
+	line      int
+	lineStart bool
+	peeked    *token
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), line: 1, lineStart: true}
+}
+
+// ReadAll reads every game of a PGN file.
+func ReadAll(r io.Reader) ([]*Game, error) {
+	reader := NewReader(r)
+	var games []*Game
+	for {
+		game, err := reader.Read()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return games, err
+		}
+		games = append(games, game)
+	}
+}
+
+// Read returns the next game, checking every move against the position it
+// is played in, or io.EOF once there are no games left.
+func (r *Reader) Read() (*Game, error) {
+	tok, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if tok.kind == TOKEN_EOF {
+		return nil, io.EOF
+	}
+	r.unread(tok)
+
+	game := &Game{}
+	for {
+		if tok, err = r.next(); err != nil {
+			return nil, err
+		}
+		if tok.kind != TOKEN_TAG_OPEN {
+			r.unread(tok)
+			break
+		}
+		if err := r.readTag(game); err != nil {
+			return nil, err
+		}
+	}
+	game.Result = results[game.Tag("Result")]
+
+	board, err := game.StartBoard()
+	if err != nil {
+		return nil, fmt.Errorf("pgn: line %d: %s", tok.line, err.Error())
+	}
+	p := &parser{Reader: r, game: game}
+	if game.Moves, err = p.readLine(board, 0); err != nil {
+		return nil, err
+	}
+	game.SetTag("Result", game.Result.String())
+	return game, nil
+}
+
+func (r *Reader) readTag(game *Game) error {
+	name, err := r.expect(TOKEN_SYMBOL, "tag name")
+	if err != nil {
+		return err
+	}
+	value, err := r.expect(TOKEN_STRING, "tag value")
+	if err != nil {
+		return err
+	}
+	if _, err := r.expect(TOKEN_TAG_CLOSE, "]"); err != nil {
+		return err
+	}
+	game.SetTag(name.text, value.text)
+	return nil
+}
+
+func (r *Reader) expect(kind tokenKind, what string) (token, error) {
+	tok, err := r.next()
+	if err != nil {
+		return tok, err
+	}
+	if tok.kind != kind {
+		return tok, fmt.Errorf("pgn: line %d: expected %s, found %q", tok.line, what, tok.text)
+	}
+	return tok, nil
+}
+
+// parser reads the movetext of one game.
+type parser struct {
+	*Reader
+	game *Game
+}
+
+// readLine reads moves up to the result, the end of a variation, the next
+// game or the end of the input. A variation leaves board as it found it.
+func (p *parser) readLine(board *chessEngine.Board, depth int) ([]*Node, error) {
+	var line []*Node
+	var before string
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		var last *Node
+		if len(line) > 0 {
+			last = line[len(line)-1]
+		}
+		switch tok.kind {
+		case TOKEN_EOF, TOKEN_TAG_OPEN:
+			if depth > 0 {
+				return nil, fmt.Errorf("pgn: line %d: unterminated variation", tok.line)
+			}
+			p.unread(tok)
+			p.keepComment(line, before)
+			return line, nil
+		case TOKEN_COMMENT:
+			if last == nil {
+				before = joinComment(before, tok.text)
+			} else {
+				last.Comment = joinComment(last.Comment, tok.text)
+			}
+		case TOKEN_NAG:
+			if last == nil {
+				return nil, fmt.Errorf("pgn: line %d: annotation $%s before any move", tok.line, tok.text)
+			}
+			nag, err := strconv.Atoi(tok.text)
+			if err != nil || nag > 255 {
+				return nil, fmt.Errorf("pgn: line %d: invalid annotation $%s", tok.line, tok.text)
+			}
+			last.NAGs = append(last.NAGs, nag)
+		case TOKEN_VAR_OPEN:
+			if last == nil {
+				return nil, fmt.Errorf("pgn: line %d: variation before any move", tok.line)
+			}
+			board.Pop()
+			variation, err := p.readLine(board, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			board.Push(last.Move)
+			if len(variation) > 0 {
+				last.Variations = append(last.Variations, variation)
+			}
+		case TOKEN_VAR_CLOSE:
+			if depth == 0 {
+				return nil, fmt.Errorf("pgn: line %d: unexpected )", tok.line)
+			}
+			for range line {
+				board.Pop()
+			}
+			return line, nil
+		case TOKEN_SYMBOL:
+			if result, ok := results[tok.text]; ok {
+				if depth > 0 {
+					return nil, fmt.Errorf("pgn: line %d: result inside a variation", tok.line)
+				}
+				p.game.Result = result
+				p.keepComment(line, before)
+				return line, nil
+			}
+			if isMoveNumber(tok.text) {
+				continue
+			}
+			node, err := readMove(board, tok)
+			if err != nil {
+				return nil, err
+			}
+			if last == nil {
+				node.CommentBefore = before
+			}
+			line = append(line, node)
+			board.Push(node.Move)
+		default:
+			return nil, fmt.Errorf("pgn: line %d: unexpected %q in movetext", tok.line, tok.text)
+		}
+	}
+}
+
+// keepComment keeps the comment read before the first move of the main
+// line on the game when there is no such move.
+func (p *parser) keepComment(line []*Node, before string) {
+	if len(line) == 0 {
+		p.game.Comment = before
+	}
+}
+
+func readMove(board *chessEngine.Board, tok token) (*Node, error) {
+	san := strings.TrimRight(tok.text, "!?")
+	node := &Node{}
+	if suffix := tok.text[len(san):]; suffix != "" {
+		nag, ok := suffixNAGs[suffix]
+		if !ok {
+			return nil, fmt.Errorf("pgn: line %d: invalid annotation %q", tok.line, suffix)
+		}
+		node.NAGs = append(node.NAGs, nag)
+	}
+	move, err := board.ParseSAN(san)
+	if err != nil {
+		return nil, fmt.Errorf("pgn: line %d: %s", tok.line, err.Error())
+	}
+	node.Move = move
+	node.SAN = board.SAN(move)
+	return node, nil
+}
+
+func isMoveNumber(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func joinComment(comment, text string) string {
+	if comment == "" {
+		return text
+	}
+	return comment + " " + text
+}
+
+func (r *Reader) unread(tok token) {
+	r.peeked = &tok
+}
+
+// next returns the next token, skipping whitespace, periods, ";" comments
+// to the end of the line and "%" escaped lines.
+func (r *Reader) next() (token, error) {
+	if r.peeked != nil {
+		tok := *r.peeked
+		r.peeked = nil
+		return tok, nil
+	}
+	for {
+		c, err := r.r.ReadByte()
+		if err == io.EOF {
+			return token{kind: TOKEN_EOF, line: r.line}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+		line, atLineStart := r.line, r.lineStart
+		r.lineStart = false
+		switch {
+		case c == '%' && atLineStart, c == ';':
+			if _, err := r.readUntil('\n'); err != nil {
+				return token{kind: TOKEN_EOF, line: line}, nil
+			}
+			continue
+		case c == '\n':
+			r.line++
+			r.lineStart = true
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '.':
+		case c == '[':
+			return token{TOKEN_TAG_OPEN, "[", line}, nil
+		case c == ']':
+			return token{TOKEN_TAG_CLOSE, "]", line}, nil
+		case c == '(':
+			return token{TOKEN_VAR_OPEN, "(", line}, nil
+		case c == ')':
+			return token{TOKEN_VAR_CLOSE, ")", line}, nil
+		case c == '{':
+			text, err := r.readUntil('}')
+			if err != nil {
+				return token{}, fmt.Errorf("pgn: line %d: unterminated comment", line)
+			}
+			return token{TOKEN_COMMENT, strings.Join(strings.Fields(text), " "), line}, nil
+		case c == '"':
+			text, err := r.readString()
+			if err != nil {
+				return token{}, fmt.Errorf("pgn: line %d: unterminated string", line)
+			}
+			return token{TOKEN_STRING, text, line}, nil
+		case c == '$':
+			return token{TOKEN_NAG, r.readSymbol(""), line}, nil
+		case isSymbolByte(c):
+			return token{TOKEN_SYMBOL, r.readSymbol(string(c)), line}, nil
+		default:
+			return token{}, fmt.Errorf("pgn: line %d: unexpected character %q", line, c)
+		}
+	}
+}
+
+// readUntil reads up to and including delim, counting the lines it spans.
+func (r *Reader) readUntil(delim byte) (string, error) {
+	text, err := r.r.ReadString(delim)
+	r.line += strings.Count(text, "\n")
+	if err != nil {
+		return text, err
+	}
+	if delim == '\n' {
+		r.lineStart = true
+	}
+	return text[:len(text)-1], nil
+}
+
+// readString reads a tag value up to its closing quote, handling the \"
+// and \\ escapes.
+func (r *Reader) readString() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if c, err = r.r.ReadByte(); err != nil {
+				return "", err
+			}
+		case '\n':
+			return "", errors.New("newline in string")
+		}
+		sb.WriteByte(c)
+	}
+}
+
+func (r *Reader) readSymbol(prefix string) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			return sb.String()
+		}
+		if !isSymbolByte(c) {
+			r.r.UnreadByte()
+			return sb.String()
+		}
+		sb.WriteByte(c)
+	}
+}
+
+func isSymbolByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("_+#=:-/!?*", c) >= 0
+}
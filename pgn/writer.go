@@ -0,0 +1,142 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// MAX_LINE_LENGTH is where movetext lines are wrapped, as PGN export format
+// asks for lines shorter than 80 characters.
+const MAX_LINE_LENGTH = 79
+
+// Write writes the game in PGN export format followed by a blank line, so
+// that games written one after another make a valid multi-game file.
+func Write(w io.Writer, g *Game) error {
+	_, err := io.WriteString(w, g.String()+"\n")
+	return err
+}
+
+// String formats the game in PGN export format: the Seven Tag Roster, the
+// other tags, then the movetext ending with the result. A FEN tag always
+// comes with [SetUp "1"] before it.
+func (g *Game) String() string {
+	var sb strings.Builder
+	for _, name := range SEVEN_TAG_ROSTER {
+		value := g.Tag(name)
+		if name == "Result" {
+			value = g.Result.String()
+		} else if value == "" {
+			value = "?"
+		}
+		writeTag(&sb, name, value)
+	}
+	for _, tag := range g.Tags {
+		switch {
+		case slices.Contains(SEVEN_TAG_ROSTER[:], tag.Name):
+		case tag.Name == "SetUp" && g.Tag("FEN") != "":
+			// written with the FEN tag, which needs it set to 1
+		case tag.Name == "FEN":
+			writeTag(&sb, "SetUp", "1")
+			writeTag(&sb, tag.Name, tag.Value)
+		default:
+			writeTag(&sb, tag.Name, tag.Value)
+		}
+	}
+	sb.WriteByte('\n')
+
+	ply := 0
+	if board, err := g.StartBoard(); err == nil {
+		ply = (board.MoveNumber() - 1) * 2
+		if board.Turn() {
+			ply++
+		}
+	}
+	mt := &movetext{}
+	if g.Comment != "" {
+		mt.comment(g.Comment)
+	}
+	mt.line(g.Moves, ply)
+	mt.word(g.Result.String())
+	sb.WriteString(mt.sb.String())
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+func writeTag(sb *strings.Builder, name, value string) {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	fmt.Fprintf(sb, "[%s \"%s\"]\n", name, value)
+}
+
+// movetext lays out words, wrapping lines before they get too long.
+type movetext struct {
+	sb         strings.Builder
+	lineLength int
+	// the next word follows the previous one without a space, after "("
+	glue bool
+}
+
+func (mt *movetext) word(word string) {
+	switch {
+	case mt.glue || mt.sb.Len() == 0:
+	case mt.lineLength+1+len(word) > MAX_LINE_LENGTH:
+		mt.sb.WriteByte('\n')
+		mt.lineLength = 0
+	default:
+		mt.sb.WriteByte(' ')
+		mt.lineLength++
+	}
+	mt.sb.WriteString(word)
+	mt.lineLength += len(word)
+	mt.glue = false
+}
+
+func (mt *movetext) comment(text string) {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		mt.word("{}")
+		return
+	}
+	words[0] = "{" + words[0]
+	words[len(words)-1] += "}"
+	for _, word := range words {
+		mt.word(word)
+	}
+}
+
+// line writes moves starting at ply, the number of half moves since the
+// start of the game, with their annotations and variations. Black moves
+// only get a number at the start of a line or after a comment or variation.
+func (mt *movetext) line(nodes []*Node, ply int) {
+	number := true
+	for _, node := range nodes {
+		if node.CommentBefore != "" {
+			mt.comment(node.CommentBefore)
+		}
+		if ply%2 == 0 {
+			mt.word(fmt.Sprintf("%d.", ply/2+1))
+		} else if number {
+			mt.word(fmt.Sprintf("%d...", ply/2+1))
+		}
+		mt.word(node.SAN)
+		for _, nag := range node.NAGs {
+			mt.word(fmt.Sprintf("$%d", nag))
+		}
+		number = false
+		if node.Comment != "" {
+			mt.comment(node.Comment)
+			number = true
+		}
+		for _, variation := range node.Variations {
+			mt.word("(")
+			mt.glue = true
+			mt.line(variation, ply)
+			mt.glue = true
+			mt.word(")")
+			number = true
+		}
+		ply++
+	}
+}
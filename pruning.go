@@ -0,0 +1,112 @@
+package chessEngine
+
+const (
+	// null move pruning is only tried this many plies from the horizon or
+	// more, and searches the null move less deep by nullMoveReduction
+	NULL_MOVE_MIN_DEPTH = 3
+	// late move reductions start this far from the horizon, after the
+	// first LMR_FULL_MOVES moves
+	LMR_MIN_DEPTH  = 3
+	LMR_FULL_MOVES = 3
+	// futility and reverse futility pruning only happen this close to the
+	// horizon
+	FUTILITY_DEPTH         = 2
+	REVERSE_FUTILITY_DEPTH = 3
+)
+
+// How far off the static evaluation may be per ply left for the futility
+// prunings.
+const (
+	FUTILITY_MARGIN         Score = 200
+	REVERSE_FUTILITY_MARGIN Score = 120
+)
+
+// isNullWindow tells whether alpha and beta leave no score between them,
+// as for every node off the principal variation in a PVS search.
+func isNullWindow(alpha, beta Score) bool {
+	return alpha+1 >= beta
+}
+
+// nullMoveReduction is how many plies less than the moves the null move is
+// searched, more the further the horizon is.
+func nullMoveReduction(depth int) int {
+	if depth > 6 {
+		return 3
+	}
+	return 2
+}
+
+// lmrReduction is how many plies less the ith move is searched, always
+// leaving at least one.
+func lmrReduction(depth, i int) int {
+	reduction := 1
+	if i >= 2*LMR_FULL_MOVES && depth >= 6 {
+		reduction = 2
+	}
+	return min(reduction, depth-2)
+}
+
+// prune tries to settle a node off the principal variation without looking
+// at its moves, from its static evaluation, returning the score and whether
+// it did. The side to move isn't in check. Nothing is pruned while a mate
+// score bounds the window, a static evaluation says nothing about mates.
+func (n *Node) prune(depth int, turn bool, alpha, beta, staticEval Score) (Score, bool) {
+	if alpha <= -MATE_BOUND || alpha >= MATE_BOUND || beta <= -MATE_BOUND || beta >= MATE_BOUND {
+		return 0, false
+	}
+	options := n.search.options
+	if options.ReverseFutility && depth <= REVERSE_FUTILITY_DEPTH {
+		margin := REVERSE_FUTILITY_MARGIN * Score(depth)
+		if !turn && staticEval-margin >= beta {
+			return staticEval - margin, true
+		}
+		if turn && staticEval+margin <= alpha {
+			return staticEval + margin, true
+		}
+	}
+	if options.NullMove && !n.nullMove && depth >= NULL_MOVE_MIN_DEPTH && n.board.hasPieces(turn) {
+		if !turn && staticEval >= beta {
+			return n.searchNullMove(depth, turn, alpha, beta)
+		}
+		if turn && staticEval <= alpha {
+			return n.searchNullMove(depth, turn, alpha, beta)
+		}
+	}
+	return 0, false
+}
+
+// searchNullMove lets the opponent move twice: if the side to move still
+// beats the window, a real move would too. A mate found that way can't be
+// trusted, so the bound is returned instead.
+func (n *Node) searchNullMove(depth int, turn bool, alpha, beta Score) (Score, bool) {
+	child := &Node{board: n.board, search: n.search, ply: n.ply + 1, nullMove: true}
+	reduced := max(depth-1-nullMoveReduction(depth), 0)
+	n.board.pushNull()
+	defer n.board.popNull()
+	if !turn {
+		eval, _ := child.EvaluateTreeWithPruning(reduced, !turn, beta-1, beta)
+		if eval >= beta && !n.search.stop.Load() {
+			return beta, true
+		}
+		return 0, false
+	}
+	eval, _ := child.EvaluateTreeWithPruning(reduced, !turn, alpha, alpha+1)
+	if eval <= alpha && !n.search.stop.Load() {
+		return alpha, true
+	}
+	return 0, false
+}
+
+// isFutile tells whether, this close to the horizon, the static evaluation
+// is so far from the window that only captures, promotions and checks are
+// worth searching.
+func (n *Node) isFutile(depth int, turn bool, alpha, beta, staticEval Score) bool {
+	if !n.search.options.Futility || depth > FUTILITY_DEPTH {
+		return false
+	}
+	margin := FUTILITY_MARGIN * Score(depth)
+	if !turn {
+		return staticEval+margin <= alpha
+	}
+	return staticEval-margin >= beta
+}
@@ -0,0 +1,102 @@
+package chessEngine
+
+// DELTA_MARGIN is how much more than the piece it takes a capture is allowed
+// to gain before delta pruning gives up on it.
+const DELTA_MARGIN Score = 200
+
+// quiescence scores a position at the horizon by searching only the moves
+// that change the material, until the position is quiet. The side to move
+// may also stand pat, taking the static evaluation, as it is rarely forced
+// to capture; in check it must find an evasion and every move is searched.
+// ply counts the plies searched past the horizon.
+func (n *Node) quiescence(turn bool, alpha, beta Score, ply int) Score {
+	// the horizon node was already counted by the main search
+	n.search.qnodes++
+	if ply > 0 && n.search.visit() {
+		return 0
+	}
+	inCheck := n.board.InCheck(turn)
+	standPat := n.Evaluate(n.board, turn)
+	best := standPat
+	if inCheck {
+		best = -INFINITE_SCORE
+		if turn {
+			best = INFINITE_SCORE
+		}
+	} else if !turn {
+		if standPat >= beta {
+			return standPat
+		}
+		alpha = max(alpha, standPat)
+	} else {
+		if standPat <= alpha {
+			return standPat
+		}
+		beta = min(beta, standPat)
+	}
+
+	moves := n.board.GenerateMoves(turn)
+	if len(moves) == 0 {
+		return n.terminalEval(turn, n.ply+ply)
+	}
+	n.board.orderCaptures(moves)
+	for _, move := range moves {
+		if !inCheck && !n.isNoisy(move, ply) {
+			continue
+		}
+		if !inCheck && n.deltaPrunes(move, turn, standPat, alpha, beta) {
+			continue
+		}
+		n.board.Push(move)
+		eval := n.quiescence(!turn, alpha, beta, ply+1)
+		n.board.Pop()
+		if n.search.stop.Load() {
+			return 0
+		}
+		if !turn {
+			best = max(best, eval)
+			alpha = max(alpha, eval)
+		} else {
+			best = min(best, eval)
+			beta = min(beta, eval)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// isNoisy tells whether the quiescence search looks at move: captures and
+// promotions, and on its first ply checks if the options ask for them.
+func (n *Node) isNoisy(move Move, ply int) bool {
+	if n.board.isCapture(move) || move.promotion != -1 {
+		return true
+	}
+	if ply > 0 || !n.search.options.QuiescenceChecks {
+		return false
+	}
+	n.board.Push(move)
+	check := n.board.InCheck(!move.piece.Color())
+	n.board.Pop()
+	return check
+}
+
+// deltaPrunes tells whether even winning the piece move takes, and then
+// some, leaves the side to move short of the score it already has.
+func (n *Node) deltaPrunes(move Move, turn bool, standPat, alpha, beta Score) bool {
+	if move.promotion != -1 || !n.board.isCapture(move) {
+		return false
+	}
+	gain := DELTA_MARGIN
+	if captured := n.board.Get(move.final); captured != -1 {
+		gain += Score(PIECE_VALUE[captured]) * PAWN_SCORE
+	} else {
+		// en passant
+		gain += PAWN_SCORE
+	}
+	if !turn {
+		return standPat+gain < alpha
+	}
+	return standPat-gain > beta
+}
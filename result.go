@@ -0,0 +1,129 @@
+package chessEngine
+
+type GameResult int
+
+const (
+	ONGOING    GameResult = 0
+	WHITE_WINS GameResult = 1
+	BLACK_WINS GameResult = 2
+	DRAW       GameResult = 3
+)
+
+func (r GameResult) String() string {
+	switch r {
+	case WHITE_WINS:
+		return "1-0"
+	case BLACK_WINS:
+		return "0-1"
+	case DRAW:
+		return "1/2-1/2"
+	}
+	return "*"
+}
+
+// Termination is the reason a game ended.
+type Termination int
+
+const (
+	NO_TERMINATION        Termination = 0
+	CHECKMATE             Termination = 1
+	STALEMATE             Termination = 2
+	FIFTY_MOVE_RULE       Termination = 3
+	THREEFOLD_REPETITION  Termination = 4
+	INSUFFICIENT_MATERIAL Termination = 5
+	TIME_FORFEIT          Termination = 6
+)
+
+func (t Termination) String() string {
+	switch t {
+	case CHECKMATE:
+		return "checkmate"
+	case STALEMATE:
+		return "stalemate"
+	case FIFTY_MOVE_RULE:
+		return "fifty-move rule"
+	case THREEFOLD_REPETITION:
+		return "threefold repetition"
+	case INSUFFICIENT_MATERIAL:
+		return "insufficient material"
+	case TIME_FORFEIT:
+		return "time forfeit"
+	}
+	return "none"
+}
+
+// InCheck reports whether side's king is attacked.
+func (b *Board) InCheck(side bool) bool {
+	king := WHITE_KING
+	if side {
+		king = BLACK_KING
+	}
+	return b.attackersOf(b.pieces[king].LSB(), !side) != 0
+}
+
+// Status tells whether the game is over for the side to move and why.
+func (b *Board) Status() (GameResult, Termination) {
+	if len(b.GenerateMoves(b.turn)) == 0 {
+		if !b.InCheck(b.turn) {
+			return DRAW, STALEMATE
+		}
+		if b.turn {
+			return WHITE_WINS, CHECKMATE
+		}
+		return BLACK_WINS, CHECKMATE
+	}
+	if reason := b.drawReason(2); reason != NO_TERMINATION {
+		return DRAW, reason
+	}
+	return ONGOING, NO_TERMINATION
+}
+
+// drawReason returns why the position is drawn regardless of the moves
+// available, counting it as a repetition once it has occurred repetitions
+// times before. The search passes 1 since a side that can repeat a position
+// once can keep doing it.
+func (b *Board) drawReason(repetitions int) Termination {
+	if b.halfMoves >= 100 {
+		return FIFTY_MOVE_RULE
+	}
+	if b.Repetitions() >= repetitions {
+		return THREEFOLD_REPETITION
+	}
+	if b.IsInsufficientMaterial() {
+		return INSUFFICIENT_MATERIAL
+	}
+	return NO_TERMINATION
+}
+
+// Repetitions counts how many times the current position occurred earlier
+// in the game. Only positions since the last capture or pawn move can
+// repeat, and only those with the same side to move.
+func (b *Board) Repetitions() int {
+	if b.halfMoves < 4 || len(b.undos) < 4 {
+		return 0
+	}
+	current := b.Hash()
+	count := 0
+	for i := len(b.undos) - 2; i >= 0 && i >= len(b.undos)-b.halfMoves; i -= 2 {
+		if b.undos[i].hash == current {
+			count++
+		}
+	}
+	return count
+}
+
+// IsInsufficientMaterial reports whether neither side can possibly mate:
+// bare kings, a single minor piece, or only bishops all on one square color.
+func (b *Board) IsInsufficientMaterial() bool {
+	heavy := b.pieces[WHITE_QUEEN] | b.pieces[BLACK_QUEEN] | b.pieces[WHITE_ROOK] | b.pieces[BLACK_ROOK] |
+		b.pieces[WHITE_PAWN] | b.pieces[BLACK_PAWN]
+	if heavy != 0 {
+		return false
+	}
+	knights := b.pieces[WHITE_KNIGHT] | b.pieces[BLACK_KNIGHT]
+	bishops := b.pieces[WHITE_BISHOP] | b.pieces[BLACK_BISHOP]
+	if knights.Count()+bishops.Count() <= 1 {
+		return true
+	}
+	return knights == 0 && (bishops&LIGHT_SQUARES == 0 || bishops&^LIGHT_SQUARES == 0)
+}
@@ -0,0 +1,85 @@
+package chessEngine
+
+import "testing"
+
+// loadGame sets up fen and plays the moves after it, written like "e2e4".
+func loadGame(t *testing.T, fen string, moves ...string) *Board {
+	t.Helper()
+	board := loadBoard(t, fen)
+	play(t, board, moves...)
+	return board
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		board       *Board
+		result      GameResult
+		termination Termination
+	}{
+		{"fool's mate", loadGame(t, START_FEN, "f2f3", "e7e5", "g2g4", "d8h4"), BLACK_WINS, CHECKMATE},
+		{"back rank mate", loadGame(t, "6k1/5ppp/8/8/8/8/8/4R1K1 w - - 0 1", "e1e8"), WHITE_WINS, CHECKMATE},
+		{"stalemate", loadGame(t, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"), DRAW, STALEMATE},
+		{"start", loadGame(t, START_FEN), ONGOING, NO_TERMINATION},
+		{"check", loadGame(t, START_FEN, "e2e4", "f7f5", "d1h5"), ONGOING, NO_TERMINATION},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, termination := test.board.Status()
+			if result != test.result || termination != test.termination {
+				t.Errorf("status %s (%s), expected %s (%s)", result, termination, test.result, test.termination)
+			}
+		})
+	}
+
+	// Run stops at once on a finished game and reports how it ended
+	ne, err := NewNoobEngineFromFEN("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ne.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if result, termination := ne.Result(); result != BLACK_WINS || termination != CHECKMATE {
+		t.Errorf("Run ended with %s (%s)", result, termination)
+	}
+	if s := BLACK_WINS.String() + " " + CHECKMATE.String(); s != "0-1 checkmate" {
+		t.Errorf("result written %q", s)
+	}
+}
+
+func TestDrawRules(t *testing.T) {
+	shuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	twice := append(append([]string{}, shuffle...), shuffle...)
+	tests := []struct {
+		name   string
+		board  *Board
+		result GameResult
+		reason Termination
+	}{
+		{"fifty moves", loadGame(t, "4k3/8/8/8/8/8/8/R3K3 w - - 99 80", "a1a2"), DRAW, FIFTY_MOVE_RULE},
+		{"forty-nine and a half moves", loadGame(t, "4k3/8/8/8/8/8/8/R3K3 w - - 98 80", "a1a2"), ONGOING, NO_TERMINATION},
+		{"pawn move resets the clock", loadGame(t, "4k3/8/8/8/8/8/P7/R3K3 w - - 99 80", "a2a3"), ONGOING, NO_TERMINATION},
+		{"threefold repetition", loadGame(t, START_FEN, twice...), DRAW, THREEFOLD_REPETITION},
+		{"twofold repetition", loadGame(t, START_FEN, shuffle...), ONGOING, NO_TERMINATION},
+		{"king against king", loadGame(t, "4k3/8/8/8/8/8/8/4K3 w - - 0 1"), DRAW, INSUFFICIENT_MATERIAL},
+		{"king and bishop", loadGame(t, "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1"), DRAW, INSUFFICIENT_MATERIAL},
+		{"king and knight", loadGame(t, "4k3/8/8/8/8/8/8/1N2K3 w - - 0 1"), DRAW, INSUFFICIENT_MATERIAL},
+		{"bishops on the same colour", loadGame(t, "4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1"), DRAW, INSUFFICIENT_MATERIAL},
+		{"bishops on both colours", loadGame(t, "2b1k3/8/8/8/8/8/8/2B1K3 w - - 0 1"), ONGOING, NO_TERMINATION},
+		{"two knights", loadGame(t, "4k3/8/8/8/8/8/8/1N2K1N1 w - - 0 1"), ONGOING, NO_TERMINATION},
+		{"king and pawn", loadGame(t, "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"), ONGOING, NO_TERMINATION},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, reason := test.board.Status()
+			if result != test.result || reason != test.reason {
+				t.Errorf("status %s (%s), expected %s (%s)", result, reason, test.result, test.reason)
+			}
+		})
+	}
+
+	if n := loadGame(t, START_FEN, twice...).Repetitions(); n != 2 {
+		t.Errorf("start position repeated %d times, expected 2", n)
+	}
+}
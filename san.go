@@ -0,0 +1,217 @@
+package chessEngine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (b *Board) isCapture(move Move) bool {
+	if b.Get(move.final) != -1 {
+		return true
+	}
+	return move.piece.IsPawn() && b.enPassant != nil && move.final.Equal(b.enPassant)
+}
+
+// SAN writes a legal move in Standard Algebraic Notation, e.g. "Nbd7",
+// "exd5", "O-O-O" or "e8=Q+", for the position on the board.
+func (b *Board) SAN(move Move) string {
+	var sb strings.Builder
+	if move.castling {
+		if move.final.GetFile() == 6 {
+			sb.WriteString("O-O")
+		} else {
+			sb.WriteString("O-O-O")
+		}
+	} else {
+		capture := b.isCapture(move)
+		if move.piece.IsPawn() {
+			if capture {
+				sb.WriteByte(byte('a' + move.init.GetFile()))
+			}
+		} else {
+			sb.WriteByte(pieceFENChars[move.piece%6])
+			sb.WriteString(b.disambiguation(move))
+		}
+		if capture {
+			sb.WriteByte('x')
+		}
+		sb.WriteString(squareNotation(move.final))
+		if move.promotion != -1 {
+			sb.WriteByte('=')
+			sb.WriteByte(pieceFENChars[move.promotion%6])
+		}
+	}
+
+	b.Push(move)
+	if b.InCheck(b.turn) {
+		if len(b.GenerateMoves(b.turn)) == 0 {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('+')
+		}
+	}
+	b.Pop()
+	return sb.String()
+}
+
+// disambiguation returns the shortest origin hint telling move apart from
+// the other legal moves of the same kind of piece to the same square: the
+// file if that is enough, else the rank, else both.
+func (b *Board) disambiguation(move Move) string {
+	sameFile, sameRank, others := false, false, false
+	for _, other := range b.GenerateMoves(b.turn) {
+		if other.piece != move.piece || !other.final.Equal(move.final) || other.init.Equal(move.init) || other.castling {
+			continue
+		}
+		others = true
+		sameFile = sameFile || other.init.GetFile() == move.init.GetFile()
+		sameRank = sameRank || other.init.GetRank() == move.init.GetRank()
+	}
+	switch {
+	case !others:
+		return ""
+	case !sameFile:
+		return string(rune('a' + move.init.GetFile()))
+	case !sameRank:
+		return string(rune('1' + move.init.GetRank()))
+	}
+	return squareNotation(move.init)
+}
+
+// SANLine writes moves played one after another from the position, such as
+// a principal variation, in SAN. The board is left as it is.
+func (b *Board) SANLine(moves []Move) []string {
+	line := b.Clone()
+	sans := make([]string, len(moves))
+	for i, move := range moves {
+		sans[i] = line.SAN(move)
+		line.Push(move)
+	}
+	return sans
+}
+
+// ParseSAN finds the legal move written in Standard Algebraic Notation.
+// Check, mate and annotation suffixes are accepted but not required, and
+// castling may be written with zeros.
+func (b *Board) ParseSAN(san string) (Move, error) {
+	notation := strings.TrimRight(san, "+#!?")
+	if notation == "" {
+		return Move{}, fmt.Errorf("invalid SAN %q: empty move", san)
+	}
+	moves := b.GenerateMoves(b.turn)
+
+	switch strings.ReplaceAll(notation, "0", "O") {
+	case "O-O", "O-O-O":
+		kingside := len(notation) == 3
+		for _, move := range moves {
+			if move.castling && (move.final.GetFile() == 6) == kingside {
+				return move, nil
+			}
+		}
+		return Move{}, fmt.Errorf("invalid SAN %q: castling is not legal", san)
+	}
+
+	piece := WHITE_PAWN
+	if c := notation[0]; c >= 'A' && c <= 'Z' {
+		p, ok := fenPieces[c]
+		if !ok || p == WHITE_PAWN {
+			return Move{}, fmt.Errorf("invalid SAN %q: unexpected piece %q", san, c)
+		}
+		piece = p
+		notation = notation[1:]
+	}
+	if b.turn {
+		piece += BLACK_KING
+	}
+
+	promotion := Piece(-1)
+	if i := strings.IndexByte(notation, '='); i >= 0 || (len(notation) > 2 && strings.IndexByte("QRBN", notation[len(notation)-1]) >= 0) {
+		if i < 0 {
+			i = len(notation) - 1
+		}
+		suffix := strings.TrimPrefix(notation[i:], "=")
+		p, ok := Piece(-1), false
+		if len(suffix) == 1 {
+			p, ok = fenPieces[suffix[0]]
+		}
+		if !ok || p == WHITE_KING || p == WHITE_PAWN || !piece.IsPawn() {
+			return Move{}, fmt.Errorf("invalid SAN %q: unexpected promotion %q", san, notation[i:])
+		}
+		promotion = p
+		if b.turn {
+			promotion += BLACK_KING
+		}
+		notation = notation[:i]
+	}
+
+	capture := strings.Contains(notation, "x")
+	notation = strings.Replace(notation, "x", "", 1)
+	if len(notation) < 2 {
+		return Move{}, fmt.Errorf("invalid SAN %q: missing destination square", san)
+	}
+	final, err := parseSquare(notation[len(notation)-2:])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid SAN %q: %s", san, err.Error())
+	}
+	from := notation[:len(notation)-2]
+	if len(from) > 2 {
+		return Move{}, fmt.Errorf("invalid SAN %q: unexpected %q", san, from)
+	}
+
+	var found []Move
+	for _, move := range moves {
+		if move.piece != piece || !move.final.Equal(final) || move.castling {
+			continue
+		}
+		if !matchesOrigin(move.init, from) {
+			continue
+		}
+		if move.promotion != promotion {
+			if promotion == -1 {
+				return Move{}, fmt.Errorf("invalid SAN %q: missing promotion piece", san)
+			}
+			continue
+		}
+		found = append(found, move)
+	}
+	switch {
+	case len(found) == 0:
+		return Move{}, fmt.Errorf("invalid SAN %q: no such legal move", san)
+	case len(found) > 1:
+		return Move{}, fmt.Errorf("invalid SAN %q: ambiguous move", san)
+	case capture && !b.isCapture(found[0]):
+		return Move{}, fmt.Errorf("invalid SAN %q: move is not a capture", san)
+	}
+	return found[0], nil
+}
+
+// matchesOrigin checks a SAN disambiguation, a file, a rank or a square.
+func matchesOrigin(init Position, from string) bool {
+	for i := 0; i < len(from); i++ {
+		c := from[i]
+		switch {
+		case c >= 'a' && c <= 'h':
+			if int16(c-'a') != init.GetFile() {
+				return false
+			}
+		case c >= '1' && c <= '8':
+			if int16(c-'1') != init.GetRank() {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// MakeMoveByNotation plays a move written in Standard Algebraic Notation.
+func (ne *NoobEngine) MakeMoveByNotation(move string) error {
+	m, err := ne.board.ParseSAN(move)
+	if err != nil {
+		return errors.New("illegal move: " + err.Error())
+	}
+	ne.board.makeMove(m)
+	return nil
+}
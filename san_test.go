@@ -0,0 +1,117 @@
+package chessEngine
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+var sanMoves = []struct {
+	fen  string
+	san  string
+	move string
+}{
+	{START_FEN, "e4", "e2e4"},
+	{START_FEN, "Nf3", "g1f3"},
+	{"r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 4 4", "O-O", "e1g1"},
+	{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "O-O-O", "e8c8"},
+	{"rnbqkb1r/pppppppp/5n2/3P4/8/8/PPP1PPPP/RNBQKBNR w KQkq - 1 2", "Nd2", "b1d2"},
+	{"r1bqkbnr/pppp1ppp/2n5/4p3/3PP3/5N2/PPP2PPP/RNBQKB1R b KQkq - 0 3", "exd4", "e5d4"},
+	{"rnbqkbnr/ppp1pppp/8/8/8/5N2/PPPPBPPP/RNBQK2R b KQkq - 1 3", "Nd7", "b8d7"},
+	{"r1bqkb1r/pppp1ppp/2n2n2/8/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 4", "Nd4", "c6d4"},
+	{"4k3/8/8/8/8/8/8/R4RK1 w - - 0 1", "Rad1", "a1d1"},
+	{"4k3/8/8/8/R7/8/8/R3K3 w - - 0 1", "R1a2", "a1a2"},
+	{"4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1", "Qa1b2", "a1b2"},
+	{"8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8=Q", "e7e8q"},
+	{"3k4/4P3/3K4/8/8/8/8/8 w - - 0 1", "e8=N", "e7e8n"},
+	{"3r4/4P3/8/8/8/8/3k4/7K w - - 0 1", "exd8=R+", "e7d8r"},
+	{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "exf6", "e5f6"},
+	{"6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1", "Ra8#", "a1a8"},
+}
+
+func TestSAN(t *testing.T) {
+	for _, tc := range sanMoves {
+		var board Board
+		if err := board.LoadFEN(tc.fen); err != nil {
+			t.Fatal(err)
+		}
+		move, err := board.moveFromCoordinates(tc.move)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.fen, err)
+		}
+		if got := board.SAN(move); got != tc.san {
+			t.Errorf("%s: SAN(%s) = %q, expected %q", tc.fen, tc.move, got, tc.san)
+		}
+		parsed, err := board.ParseSAN(tc.san)
+		if err != nil {
+			t.Errorf("%s: ParseSAN(%q): %v", tc.fen, tc.san, err)
+		} else if NotationFromMove(parsed) != tc.move {
+			t.Errorf("%s: ParseSAN(%q) = %s, expected %s", tc.fen, tc.san, NotationFromMove(parsed), tc.move)
+		}
+	}
+}
+
+func TestParseSANErrors(t *testing.T) {
+	var board Board
+	if err := board.LoadFEN("4k3/4P3/8/8/8/8/8/R3K2R w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, san := range []string{"", "e5", "Ke2x", "Zd4", "Rhb1", "Rxa5", "O-O-O", "e8", "e8=K", "Ka2=Q", "Rh1h2h3"} {
+		if move, err := board.ParseSAN(san); err == nil {
+			t.Errorf("ParseSAN(%q) = %s, expected an error", san, NotationFromMove(move))
+		}
+	}
+}
+
+// Every legal move must read back from its own SAN.
+func TestSANRoundTrip(t *testing.T) {
+	for _, tc := range perftPositions {
+		var board Board
+		if err := board.LoadFEN(tc.fen); err != nil {
+			t.Fatal(err)
+		}
+		for _, move := range board.GenerateMoves(board.turn) {
+			board.Push(move)
+			for _, reply := range board.GenerateMoves(board.turn) {
+				san := board.SAN(reply)
+				if parsed, err := board.ParseSAN(san); err != nil || NotationFromMove(parsed) != NotationFromMove(reply) {
+					t.Errorf("%s after %s: %q read back as %s (%v)", tc.name, NotationFromMove(move), san, NotationFromMove(parsed), err)
+				}
+			}
+			board.Pop()
+		}
+	}
+}
+
+func TestMakeMoveByNotation(t *testing.T) {
+	ne, err := NewNoobEngine(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nothing may be written to stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	if err := ne.Board().MakeMove(WHITE_PAWN, square(t, "e2"), square(t, "e4")); err != nil {
+		t.Error(err)
+	}
+	for _, move := range []string{"f5", "Qh5+"} {
+		if err := ne.MakeMoveByNotation(move); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := ne.MakeMoveByNotation("Nf6"); err == nil {
+		t.Error("Nf6 played in check")
+	}
+	os.Stdout = stdout
+	w.Close()
+	if printed, _ := io.ReadAll(r); len(printed) > 0 {
+		t.Errorf("printed %q", printed)
+	}
+	if !ne.Board().inCheck {
+		t.Error("check after Qh5+ not noticed")
+	}
+}
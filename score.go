@@ -0,0 +1,52 @@
+package chessEngine
+
+import "fmt"
+
+// Score is the value of a position in centipawns, from white's point of view
+// unless said otherwise. Scores beyond MATE_BOUND either way are mates: a
+// mate delivered n plies from the root of the search scores MATE_SCORE - n
+// for the mating side, so that shorter mates score higher and getting mated
+// later is preferred.
+type Score int32
+
+const (
+	PAWN_SCORE Score = 100
+	MATE_SCORE Score = 30000
+	// every mate the search can find scores further from 0 than this
+	MATE_BOUND Score = MATE_SCORE - 1000
+	// beyond any score, the bounds of a full window
+	INFINITE_SCORE Score = 32000
+)
+
+// matedIn is the score of the side to move being mated ply plies from the
+// root, from its own point of view.
+func matedIn(ply int) Score {
+	return -MATE_SCORE + Score(ply)
+}
+
+// IsMate tells whether the score is a forced mate, by either side.
+func (s Score) IsMate() bool {
+	return s > MATE_BOUND || s < -MATE_BOUND
+}
+
+// MateIn is the number of moves until mate, positive if the side the score
+// is for mates and negative if it gets mated, or 0 if the score isn't a
+// mate. The moves are counted from the side to move at the root.
+func (s Score) MateIn() int {
+	switch {
+	case s > MATE_BOUND:
+		return int(MATE_SCORE-s+1) / 2
+	case s < -MATE_BOUND:
+		return -int(MATE_SCORE+s+1) / 2
+	}
+	return 0
+}
+
+// String writes the score in pawns, e.g. "-0.35", or a mate as "#3", or
+// "#-3" when the other side mates, the way PGN %eval comments do.
+func (s Score) String() string {
+	if s.IsMate() {
+		return fmt.Sprintf("#%d", s.MateIn())
+	}
+	return fmt.Sprintf("%.2f", float64(s)/float64(PAWN_SCORE))
+}
@@ -0,0 +1,79 @@
+package chessEngine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreString(t *testing.T) {
+	for _, test := range []struct {
+		score  Score
+		mateIn int
+		text   string
+	}{
+		{0, 0, "0.00"},
+		{-35, 0, "-0.35"},
+		{MATE_BOUND, 0, "290.00"},
+		{MATE_SCORE - 1, 1, "#1"},
+		{MATE_SCORE - 4, 2, "#2"},
+		{MATE_SCORE - 5, 3, "#3"},
+		{matedIn(2), -1, "#-1"},
+		{matedIn(6), -3, "#-3"},
+	} {
+		if mateIn := test.score.MateIn(); mateIn != test.mateIn {
+			t.Errorf("%d: mate in %d, expected %d", test.score, mateIn, test.mateIn)
+		}
+		if text := test.score.String(); text != test.text {
+			t.Errorf("%d written %q, expected %q", test.score, text, test.text)
+		}
+	}
+}
+
+func TestMateScores(t *testing.T) {
+	// Re8 mates at once, the other rook moves take longer
+	ne, err := NewNoobEngineFromFEN("6k1/5ppp/8/8/8/8/4RPPP/4R1K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := ne.Search(SearchLimits{Depth: 4}, nil)
+	if info.Eval != MATE_SCORE-1 || info.PV[0].String() != "e2e8" {
+		t.Errorf("scored %s with PV %v, expected the mate in one", info.Eval, info.PV)
+	}
+	if line := uciInfo(info, false, NotationFromMove); !strings.Contains(line, "score mate 1 ") {
+		t.Errorf("unexpected UCI info %q", line)
+	}
+
+	// whatever black plays, Qg7 mates
+	ne, err = NewNoobEngineFromFEN("7k/p7/6Q1/8/8/8/8/K5R1 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info = ne.Search(SearchLimits{Depth: 3}, nil)
+	if info.Eval != MATE_SCORE-2 {
+		t.Fatalf("scored %s with PV %v, expected black mated in one", info.Eval, info.PV)
+	}
+	if line := uciInfo(info, true, NotationFromMove); !strings.Contains(line, "score mate -1 ") {
+		t.Errorf("unexpected UCI info %q", line)
+	}
+	if line := xboardThinking(info, true, ne.Board()); !strings.HasPrefix(line, "3 -100001 ") {
+		t.Errorf("unexpected thinking output %q", line)
+	}
+}
+
+func TestTranspositionMateScores(t *testing.T) {
+	// a mate 3 plies from a node 4 plies deep is 7 plies from the root,
+	// and 5 from the root when the node is reached at ply 2
+	stored := scoreToTT(MATE_SCORE-7, 4)
+	if stored != MATE_SCORE-3 {
+		t.Errorf("stored as %d, expected %d", stored, MATE_SCORE-3)
+	}
+	if loaded := scoreFromTT(stored, 2); loaded != MATE_SCORE-5 {
+		t.Errorf("loaded as %d, expected %d", loaded, MATE_SCORE-5)
+	}
+	if loaded := scoreFromTT(scoreToTT(matedIn(7), 4), 2); loaded != matedIn(5) {
+		t.Errorf("mated score loaded as %d, expected %d", loaded, matedIn(5))
+	}
+	if scoreToTT(250, 4) != 250 || scoreFromTT(-250, 4) != -250 {
+		t.Error("scores other than mates changed")
+	}
+}
@@ -0,0 +1,236 @@
+package chessEngine
+
+import "time"
+
+const (
+	DEFAULT_DEPTH = 5
+	MAX_DEPTH     = 64
+	// how many moves the clock has to last when the time control doesn't say
+	DEFAULT_MOVES_TO_GO = 30
+	// kept back from every move for the front end and the GUI
+	MOVE_OVERHEAD = 10 * time.Millisecond
+	// iterations from this depth on search the root with an aspiration
+	// window, see aspirationSearch
+	ASPIRATION_DEPTH = 4
+)
+
+// ASPIRATION_WINDOW is how far the score of an iteration may be from the
+// previous one's before the root is searched again, the margin doubling
+// every time.
+const ASPIRATION_WINDOW Score = 50
+
+// SearchLimits tells Search when to stop. Zero values mean no limit of that
+// kind; with no limits at all the engine's default depth is searched.
+type SearchLimits struct {
+	Depth     int
+	MoveTime  time.Duration
+	WhiteTime time.Duration
+	BlackTime time.Duration
+	WhiteInc  time.Duration
+	BlackInc  time.Duration
+	MovesToGo int
+	Infinite  bool
+}
+
+func (l SearchLimits) timed() bool {
+	return l.MoveTime > 0 || l.WhiteTime > 0 || l.BlackTime > 0
+}
+
+// SearchInfo is the outcome of a search to Depth plies.
+type SearchInfo struct {
+	Depth int
+	// from white's point of view, like the evaluator
+	Eval Score
+	PV   []Move
+	// every node searched so far, QNodes of them in the quiescence search
+	Nodes  uint64
+	QNodes uint64
+	// the nodes searched by this iteration alone
+	IterationNodes uint64
+	Time           time.Duration
+	// how full the transposition table is, in permille
+	HashFull int
+	// how many times so far the root had to be searched again because the
+	// score fell outside the aspiration window, below it or above it
+	FailLows  int
+	FailHighs int
+}
+
+// SearchOptions switches parts of the search on and off, to compare the
+// strength of the engine with and without them.
+type SearchOptions struct {
+	// search captures and promotions past the horizon instead of
+	// evaluating positions in the middle of an exchange
+	Quiescence bool
+	// also search checks on the first ply of the quiescence search
+	QuiescenceChecks bool
+	// let the opponent move twice in a row, and if that is still too good
+	// for the side to move, cut the node off after a shallower search
+	NullMove bool
+	// search quiet moves ordered late less deep, again fully if they turn
+	// out better than expected
+	LateMoveReductions bool
+	// skip quiet moves near the leaves when even a good one couldn't bring
+	// the score up to alpha
+	Futility bool
+	// cut nodes near the leaves off when the static evaluation beats beta
+	// by a margin
+	ReverseFutility bool
+}
+
+// DefaultSearchOptions returns the options the engine starts with.
+func DefaultSearchOptions() SearchOptions {
+	return SearchOptions{Quiescence: true, NullMove: true, LateMoveReductions: true, Futility: true, ReverseFutility: true}
+}
+
+// SetSearchOptions changes the options of the searches that follow.
+func (ne *NoobEngine) SetSearchOptions(options SearchOptions) {
+	ne.options = options
+}
+
+func (ne *NoobEngine) SearchOptions() SearchOptions {
+	return ne.options
+}
+
+// Search looks for the best move in the current position, deeper and deeper
+// until the limits are reached or Stop is called, and calls report after
+// every completed depth. The returned PV is empty only when there is no
+// legal move. A Stop that comes before the search starts stops it too; the
+// front ends clear it before starting the goroutine that searches.
+func (ne *NoobEngine) Search(limits SearchLimits, report func(SearchInfo)) SearchInfo {
+	start := time.Now()
+	ctx := &searchContext{stop: &ne.stop, tt: ne.tt, options: ne.options}
+	ne.tt.newSearch()
+	var softDeadline time.Time
+	if budget := timeBudget(limits, ne.board.turn); budget > 0 {
+		ctx.deadline = start.Add(budget)
+		if limits.MoveTime == 0 {
+			softDeadline = start.Add(budget / 2)
+		}
+	}
+	maxDepth := limits.Depth
+	if maxDepth == 0 && (limits.timed() || limits.Infinite) {
+		maxDepth = MAX_DEPTH
+	} else if maxDepth == 0 {
+		maxDepth = ne.depth
+	}
+
+	var best SearchInfo
+	for depth := 1; depth <= maxDepth; depth++ {
+		if depth > 1 && !softDeadline.IsZero() && time.Now().After(softDeadline) {
+			// the next iteration would not finish in time
+			break
+		}
+		nodes := ctx.nodes
+		eval, moves := ne.aspirationSearch(ctx, depth, best)
+		if ctx.stop.Load() && len(best.PV) > 0 {
+			break
+		}
+		best = SearchInfo{Depth: depth, Eval: eval, PV: moves, Nodes: ctx.nodes, QNodes: ctx.qnodes,
+			IterationNodes: ctx.nodes - nodes, Time: time.Since(start), HashFull: ne.tt.Usage(),
+			FailLows: ctx.failLows, FailHighs: ctx.failHighs}
+		if len(moves) > 0 {
+			ctx.pvMove = moves[0]
+		}
+		if len(moves) > 0 && report != nil {
+			report(best)
+		}
+		if ctx.stop.Load() || len(moves) == 0 {
+			break
+		}
+	}
+	if len(best.PV) == 0 {
+		// stopped before even one ply was searched
+		if moves := ne.board.GenerateMoves(ne.board.turn); len(moves) > 0 {
+			best.PV = moves[:1]
+		}
+	}
+	return best
+}
+
+// BranchingFactor is the effective branching factor of the iteration that
+// gave info, how many times more nodes it took than the one before, which
+// gave previous.
+func (info SearchInfo) BranchingFactor(previous SearchInfo) float64 {
+	if previous.IterationNodes == 0 {
+		return 0
+	}
+	return float64(info.IterationNodes) / float64(previous.IterationNodes)
+}
+
+// aspirationSearch searches the root depth plies deep. Once the previous
+// iteration's score is known the score is unlikely to move much, so the
+// window is narrowed around it, which prunes more; if the score falls
+// outside the window after all, the root is searched again with the window
+// widened on that side, ever more until it is the whole range.
+func (ne *NoobEngine) aspirationSearch(ctx *searchContext, depth int, previous SearchInfo) (Score, []Move) {
+	alpha, beta := -INFINITE_SCORE, INFINITE_SCORE
+	delta := ASPIRATION_WINDOW
+	if depth >= ASPIRATION_DEPTH && len(previous.PV) > 0 {
+		alpha, beta = previous.Eval-delta, previous.Eval+delta
+	}
+	for {
+		tree := NewNode(ne.board, depth)
+		tree.search = ctx
+		eval, moves := tree.EvaluateTreeWithPruning(depth, ne.board.turn, alpha, beta)
+		if ctx.stop.Load() {
+			return eval, moves
+		}
+		delta *= 2
+		switch {
+		case eval <= alpha && alpha > -INFINITE_SCORE:
+			ctx.failLows++
+			alpha = eval - delta
+			if delta > MATE_SCORE-MATE_BOUND || eval.IsMate() {
+				alpha = -INFINITE_SCORE
+			}
+		case eval >= beta && beta < INFINITE_SCORE:
+			ctx.failHighs++
+			beta = eval + delta
+			if delta > MATE_SCORE-MATE_BOUND || eval.IsMate() {
+				beta = INFINITE_SCORE
+			}
+		default:
+			return eval, moves
+		}
+	}
+}
+
+// Stop makes a running Search return as soon as possible with the result
+// of the deepest completed iteration.
+func (ne *NoobEngine) Stop() {
+	ne.stop.Store(true)
+}
+
+// resetStop clears a Stop left over from an earlier search. It is called
+// before the goroutine running the next search is started, so that a Stop
+// sent right after the search begins isn't lost.
+func (ne *NoobEngine) resetStop() {
+	ne.stop.Store(false)
+}
+
+// timeBudget is how long to think about the next move, or 0 to think until
+// the depth limit or Stop: an even share of the clock over the moves to go
+// plus half the increment, never more than half the clock. With a clock no
+// new iteration starts after half the budget, as it would rarely finish.
+func timeBudget(limits SearchLimits, side bool) time.Duration {
+	if limits.Infinite {
+		return 0
+	}
+	if limits.MoveTime > 0 {
+		return limits.MoveTime
+	}
+	remaining, inc := limits.WhiteTime, limits.WhiteInc
+	if side {
+		remaining, inc = limits.BlackTime, limits.BlackInc
+	}
+	if remaining <= 0 {
+		return 0
+	}
+	movesToGo := limits.MovesToGo
+	if movesToGo <= 0 {
+		movesToGo = DEFAULT_MOVES_TO_GO
+	}
+	budget := min(remaining/time.Duration(movesToGo)+inc/2, remaining/2) - MOVE_OVERHEAD
+	return max(budget, time.Millisecond)
+}
@@ -0,0 +1,257 @@
+package chessEngine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeBudget(t *testing.T) {
+	for _, test := range []struct {
+		limits SearchLimits
+		side   bool
+		budget time.Duration
+	}{
+		{SearchLimits{}, false, 0},
+		{SearchLimits{MoveTime: time.Second}, false, time.Second},
+		{SearchLimits{WhiteTime: 30 * time.Second}, false, time.Second - MOVE_OVERHEAD},
+		{SearchLimits{WhiteTime: 30 * time.Second, BlackTime: 60 * time.Second, BlackInc: 2 * time.Second}, true, 3*time.Second - MOVE_OVERHEAD},
+		{SearchLimits{WhiteTime: 10 * time.Second, MovesToGo: 5}, false, 2*time.Second - MOVE_OVERHEAD},
+		{SearchLimits{WhiteTime: 10 * time.Second, MovesToGo: 1}, false, 5*time.Second - MOVE_OVERHEAD},
+		{SearchLimits{WhiteTime: 5 * time.Millisecond}, false, time.Millisecond},
+		{SearchLimits{WhiteTime: time.Minute, Infinite: true}, false, 0},
+	} {
+		if budget := timeBudget(test.limits, test.side); budget != test.budget {
+			t.Errorf("%+v: budget %s, expected %s", test.limits, budget, test.budget)
+		}
+	}
+}
+
+func TestSearchIterations(t *testing.T) {
+	ne, err := NewNoobEngine(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var depths []int
+	var iterations []SearchInfo
+	info := ne.Search(SearchLimits{Depth: 3}, func(info SearchInfo) {
+		depths = append(depths, info.Depth)
+		iterations = append(iterations, info)
+	})
+	if len(depths) != 3 || depths[0] != 1 || depths[2] != 3 {
+		t.Fatalf("reported depths %v, expected [1 2 3]", depths)
+	}
+	first, second, third := iterations[0], iterations[1], iterations[2]
+	if first.IterationNodes != first.Nodes || third.Nodes != first.Nodes+second.IterationNodes+third.IterationNodes {
+		t.Errorf("iteration nodes %d %d %d don't add up to %d", first.IterationNodes, second.IterationNodes,
+			third.IterationNodes, third.Nodes)
+	}
+	if ebf, want := third.BranchingFactor(second), float64(third.IterationNodes)/float64(second.IterationNodes); ebf != want {
+		t.Errorf("branching factor %.2f, expected %.2f", ebf, want)
+	}
+	if info.Depth != 3 || len(info.PV) == 0 {
+		t.Errorf("unexpected result %+v", info)
+	}
+}
+
+func TestSearchStopsInTime(t *testing.T) {
+	ne, err := NewNoobEngine(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	info := ne.Search(SearchLimits{MoveTime: 100 * time.Millisecond}, nil)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("searched for %s with a 100ms budget", elapsed)
+	}
+	if len(info.PV) == 0 || info.Depth == 0 {
+		t.Errorf("no completed iteration kept: %+v", info)
+	}
+}
+
+func TestQuiescence(t *testing.T) {
+	// the d5 pawn is defended, taking it with the queen loses her
+	const fen = "4k3/8/4p3/3p4/8/8/3Q4/4K3 w - - 0 1"
+	for _, quiescence := range []bool{false, true} {
+		ne, err := NewNoobEngineFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ne.SetSearchOptions(SearchOptions{Quiescence: quiescence})
+		info := ne.Search(SearchLimits{Depth: 1}, nil)
+		if grabs := info.PV[0].String() == "d2d5"; grabs == quiescence {
+			t.Errorf("with quiescence %t: played %s, eval %s", quiescence, info.PV[0], info.Eval)
+		}
+	}
+
+	// quiet positions are left to the evaluator, checks only with the option
+	var board Board
+	if err := board.LoadFEN("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, checks := range []bool{false, true} {
+		n := NewNode(board, 0)
+		n.search.options = SearchOptions{Quiescence: true, QuiescenceChecks: checks}
+		eval := n.quiescence(false, -INFINITE_SCORE, INFINITE_SCORE, 0)
+		if mates := eval == MATE_SCORE-1; mates != checks {
+			t.Errorf("with quiescence checks %t: eval %s", checks, eval)
+		}
+	}
+}
+
+func TestMoveOrdering(t *testing.T) {
+	var board Board
+	if err := board.LoadFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	n := NewNode(board, 1)
+	n.FindChildren(board.turn)
+	hashMove, err := ParseUCIMove(&board, "a2a3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	killer, err := ParseUCIMove(&board, "a1b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.search.cutoff(n.board, killer, n.ply, 3)
+	n.orderMoves(hashMove)
+
+	captures := 0
+	for _, child := range n.children {
+		if n.board.isTactical(child.Move) {
+			captures++
+		}
+	}
+	// the hash move, the captures from BxB and QxN down to the pawn
+	// takes, then the killer
+	for i, want := range map[int]string{0: "a2a3", 1: "e2a6", 2: "f3f6", captures + 1: "a1b1"} {
+		if got := n.children[i].Move.String(); got != want {
+			t.Errorf("move %d is %s, expected %s", i, got, want)
+		}
+	}
+}
+
+func TestOrderingPrunes(t *testing.T) {
+	ne, err := NewNoobEngineFromFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ne.SetSearchOptions(SearchOptions{})
+	// searching the moves in the order they are generated takes about 9400
+	if nodes := ne.Search(SearchLimits{Depth: 3}, nil).Nodes; nodes > 5000 {
+		t.Errorf("depth 3 took %d nodes", nodes)
+	}
+}
+
+// The principal variation search must find the same score as minimax.
+func TestPVSMatchesMinimax(t *testing.T) {
+	for _, tc := range perftPositions[:7] {
+		var board Board
+		if err := board.LoadFEN(tc.fen); err != nil {
+			t.Fatal(err)
+		}
+		want, _ := NewNode(board, 3).EvaluateTree(3, board.turn)
+		got, pv := NewNode(board, 3).EvaluateTreeWithPruning(3, board.turn, -INFINITE_SCORE, INFINITE_SCORE)
+		if got != want {
+			t.Errorf("%s: PVS scored %s, minimax %s", tc.name, got, want)
+		}
+		if len(pv) != 3 {
+			t.Errorf("%s: PV %v, expected 3 moves", tc.name, pv)
+		}
+	}
+}
+
+func TestPrincipalVariation(t *testing.T) {
+	ne, err := NewNoobEngineFromFEN(perftPositions[1].fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := ne.Search(SearchLimits{Depth: 4}, nil)
+	if len(info.PV) < 2 {
+		t.Fatalf("PV %v too short", info.PV)
+	}
+	board := ne.Board().Clone()
+	for _, move := range info.PV {
+		if ok, err := board.IsLegal(move.piece, move.init, move.final); !ok {
+			t.Fatalf("PV %v: %s illegal: %v", info.PV, move, err)
+		}
+		board.Push(move)
+	}
+	if line := ne.Board().SANLine(info.PV); len(line) != len(info.PV) || ne.Board().FEN() != perftPositions[1].fen {
+		t.Errorf("SAN line %v, board changed to %s", line, ne.Board().FEN())
+	}
+}
+
+func TestSelectivePruning(t *testing.T) {
+	nodes := func(options SearchOptions) uint64 {
+		ne, err := NewNoobEngineFromFEN(perftPositions[2].fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ne.SetSearchOptions(options)
+		return ne.Search(SearchLimits{Depth: 4}, nil).Nodes
+	}
+	full := nodes(SearchOptions{Quiescence: true})
+	for _, options := range []SearchOptions{
+		{Quiescence: true, NullMove: true},
+		{Quiescence: true, LateMoveReductions: true},
+		{Quiescence: true, Futility: true},
+		{Quiescence: true, ReverseFutility: true},
+	} {
+		if n := nodes(options); n > full {
+			t.Errorf("%+v searched %d nodes, %d without pruning", options, n, full)
+		}
+	}
+	if n := nodes(DefaultSearchOptions()); n >= full {
+		t.Errorf("searched %d nodes with every pruning, %d without", n, full)
+	}
+
+	// pruning must not hide a mate in two
+	ne, err := NewNoobEngineFromFEN("r5k1/5ppp/8/8/8/8/4RPPP/4R1K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := ne.Search(SearchLimits{Depth: 4}, nil); info.Eval != MATE_SCORE-3 {
+		t.Errorf("mate in two scored %s with PV %v", info.Eval, info.PV)
+	}
+}
+
+// Null move and reverse futility pruning must leave mate scores alone.
+func TestPruningKeepsMates(t *testing.T) {
+	tests := []struct {
+		fen   string
+		depth int
+		eval  Score
+	}{
+		{"r5k1/5ppp/8/8/8/8/4RPPP/4R1K1 w - - 0 1", 4, MATE_SCORE - 3},
+		{"r1r3k1/5ppp/8/8/8/8/4RPPP/2Q1R1K1 w - - 0 1", 6, MATE_SCORE - 5},
+	}
+	for _, test := range tests {
+		ne, err := NewNoobEngineFromFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ne.SetSearchOptions(SearchOptions{Quiescence: true, NullMove: true, ReverseFutility: true})
+		if info := ne.Search(SearchLimits{Depth: test.depth}, nil); info.Eval != test.eval {
+			t.Errorf("%s: scored %s with PV %v, expected %s", test.fen, info.Eval, info.PV, test.eval)
+		}
+	}
+}
+
+func TestAspirationWindows(t *testing.T) {
+	ne, err := NewNoobEngine(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without the selective searches the score must be the minimax one
+	// however often the window was missed
+	ne.SetSearchOptions(SearchOptions{})
+	info := ne.Search(SearchLimits{Depth: ASPIRATION_DEPTH}, nil)
+	if info.FailLows+info.FailHighs == 0 {
+		t.Errorf("the score swinging from %d to %d plies missed no window", ASPIRATION_DEPTH-1, ASPIRATION_DEPTH)
+	}
+	want, _ := NewNode(*ne.Board(), ASPIRATION_DEPTH).EvaluateTree(ASPIRATION_DEPTH, false)
+	if info.Eval != want {
+		t.Errorf("scored %s, minimax %s", info.Eval, want)
+	}
+}
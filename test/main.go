@@ -2,11 +2,47 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	myChessEngine "github.com/kishanshukla-2307/chess-engine"
+	"github.com/kishanshukla-2307/chess-engine/pgn"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "perft" {
+		if err := perft(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "uci" {
+		engine, err := myChessEngine.NewNoobEngine(false)
+		if err == nil {
+			err = engine.UCI(os.Stdin, os.Stdout)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "xboard" {
+		engine, err := myChessEngine.NewNoobEngine(false)
+		if err == nil {
+			err = engine.XBoard(os.Stdin, os.Stdout)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	engine, err := myChessEngine.NewNoobEngine(false)
 	if err != nil {
 		fmt.Println(fmt.Errorf(err.Error()))
@@ -73,7 +109,48 @@ func main() {
 	// 		fmt.Println(fmt.Errorf(err.Error()))
 	// 	}
 
+	engine.SetTimeControl(time.Minute, time.Second, 0)
 	if err := engine.Run(); err != nil {
 		fmt.Println(err)
 	}
+	pgn.Write(os.Stdout, pgn.FromEngine(engine, true))
+}
+
+// perft runs "perft <depth> [fen]", printing the node count below every
+// legal move and the total.
+func perft(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: perft <depth> [fen]")
+	}
+	depth, err := strconv.Atoi(args[0])
+	if err != nil || depth < 1 {
+		return fmt.Errorf("invalid depth %q", args[0])
+	}
+	fen := myChessEngine.START_FEN
+	if len(args) > 1 {
+		fen = strings.Join(args[1:], " ")
+	}
+	var board myChessEngine.Board
+	if err := board.LoadFEN(fen); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	divide := board.Divide(depth)
+	elapsed := time.Since(start)
+
+	moves := make([]string, 0, len(divide))
+	var total uint64
+	for move, nodes := range divide {
+		moves = append(moves, move)
+		total += nodes
+	}
+	slices.Sort(moves)
+	for _, move := range moves {
+		fmt.Printf("%s: %d\n", move, divide[move])
+	}
+	fmt.Println()
+	fmt.Println("Nodes searched:", total)
+	fmt.Println("Time taken: ", elapsed)
+	return nil
 }
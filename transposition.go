@@ -0,0 +1,174 @@
+package chessEngine
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+const (
+	DEFAULT_HASH_MB = 16
+	MAX_HASH_MB     = 4096
+)
+
+// Bound tells what a stored score says about the real score of a position,
+// both from white's point of view like the evaluator.
+type Bound uint8
+
+const (
+	// the slot is empty
+	BOUND_NONE Bound = 0
+	// the score is the real score
+	BOUND_EXACT Bound = 1
+	// the real score is at least the stored one, the search failed high
+	BOUND_LOWER Bound = 2
+	// the real score is at most the stored one, the search failed low
+	BOUND_UPPER Bound = 3
+)
+
+// ttEntry is one slot of the transposition table. The best move is packed
+// into 32 bits so that entries stay small and hold no pointers.
+type ttEntry struct {
+	key   uint64
+	move  uint32
+	score Score
+	depth int8
+	bound Bound
+	age   uint8
+}
+
+// TranspositionTable remembers the positions searched, by their Zobrist
+// hash, so that a position reached again, through another move order or in
+// the next iteration, is not searched again. It has a fixed number of slots,
+// a power of two, and a position only ever goes in the slot its hash picks.
+type TranspositionTable struct {
+	entries []ttEntry
+	mask    uint64
+	// bumped by every search so that older entries are replaced first
+	age uint8
+}
+
+// NewTranspositionTable makes a table of at most mb megabytes.
+func NewTranspositionTable(mb int) (*TranspositionTable, error) {
+	tt := &TranspositionTable{}
+	if err := tt.Resize(mb); err != nil {
+		return nil, err
+	}
+	return tt, nil
+}
+
+// Resize reallocates the table to at most mb megabytes, forgetting every
+// position stored.
+func (tt *TranspositionTable) Resize(mb int) error {
+	if mb < 1 || mb > MAX_HASH_MB {
+		return fmt.Errorf("invalid hash size %d MB, expected 1 to %d", mb, MAX_HASH_MB)
+	}
+	count := uint64(mb) << 20 / uint64(unsafe.Sizeof(ttEntry{}))
+	size := uint64(1)
+	for size*2 <= count {
+		size *= 2
+	}
+	tt.entries = make([]ttEntry, size)
+	tt.mask = size - 1
+	tt.age = 0
+	return nil
+}
+
+// Clear forgets every position stored, for a new game.
+func (tt *TranspositionTable) Clear() {
+	clear(tt.entries)
+	tt.age = 0
+}
+
+// newSearch ages the entries stored so far.
+func (tt *TranspositionTable) newSearch() {
+	tt.age++
+}
+
+// Usage is how full the table is in permille, from the first thousand
+// slots, as UCI's hashfull reports it.
+func (tt *TranspositionTable) Usage() int {
+	used, sample := 0, min(1000, len(tt.entries))
+	for _, entry := range tt.entries[:sample] {
+		if entry.bound != BOUND_NONE && entry.age == tt.age {
+			used++
+		}
+	}
+	return used * 1000 / sample
+}
+
+// probe returns the entry stored for key, if any.
+func (tt *TranspositionTable) probe(key uint64) (ttEntry, bool) {
+	entry := tt.entries[key&tt.mask]
+	return entry, entry.bound != BOUND_NONE && entry.key == key
+}
+
+// store records the result of searching a position depth plies deep. The
+// slot is taken over when it is empty, holds the same position, was written
+// by an earlier search or was searched no deeper; otherwise the deeper
+// result already there is worth more and is kept. A position stored again
+// without a best move keeps the one it had.
+func (tt *TranspositionTable) store(key uint64, depth int, score Score, bound Bound, move Move) {
+	entry := &tt.entries[key&tt.mask]
+	replace := entry.bound == BOUND_NONE || entry.key == key || entry.age != tt.age || int(entry.depth) <= depth
+	if !replace {
+		return
+	}
+	packed := packMove(move)
+	if packed == 0 && entry.key == key {
+		packed = entry.move
+	}
+	*entry = ttEntry{key: key, move: packed, score: score, depth: int8(depth), bound: bound, age: tt.age}
+}
+
+// scoreToTT turns a mate score counted from the root into one counted from
+// the node ply plies from it, which is what the table keeps since the same
+// position may be reached at another ply. Other scores don't change.
+func scoreToTT(score Score, ply int) Score {
+	switch {
+	case score > MATE_BOUND:
+		return score + Score(ply)
+	case score < -MATE_BOUND:
+		return score - Score(ply)
+	}
+	return score
+}
+
+// scoreFromTT turns a score from the table back into one counted from the
+// root, for a node ply plies from it.
+func scoreFromTT(score Score, ply int) Score {
+	switch {
+	case score > MATE_BOUND:
+		return score - Score(ply)
+	case score < -MATE_BOUND:
+		return score + Score(ply)
+	}
+	return score
+}
+
+// packMove squeezes move into 32 bits: the squares in the low 12, then the
+// piece, then the promotion plus one, then castling. Only the zero Move
+// packs to 0.
+func packMove(move Move) uint32 {
+	if move.init == nil || move.final == nil {
+		return 0
+	}
+	packed := uint32(squareIndex(move.init)) | uint32(squareIndex(move.final))<<6 |
+		uint32(move.piece)<<12 | uint32(move.promotion+1)<<16
+	if move.castling {
+		packed |= 1 << 20
+	}
+	return packed | 1<<21
+}
+
+func unpackMove(packed uint32) Move {
+	if packed == 0 {
+		return Move{}
+	}
+	return Move{
+		piece:     Piece(packed >> 12 & 15),
+		init:      positionOf(int(packed & 63)),
+		final:     positionOf(int(packed >> 6 & 63)),
+		promotion: Piece(packed>>16&15) - 1,
+		castling:  packed&(1<<20) != 0,
+	}
+}
@@ -0,0 +1,74 @@
+package chessEngine
+
+import "testing"
+
+func TestTranspositionTable(t *testing.T) {
+	tt, err := NewTranspositionTable(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := len(tt.entries); size&(size-1) != 0 || size == 0 {
+		t.Fatalf("%d slots, expected a power of two", size)
+	}
+	if _, err := NewTranspositionTable(0); err == nil {
+		t.Error("0 MB table made without error")
+	}
+
+	var board Board
+	board.LoadFEN(START_FEN)
+	move, err := ParseUCIMove(&board, "e2e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := uint64(12345)
+	other := key + uint64(len(tt.entries))
+	tt.store(key, 4, 50, BOUND_LOWER, move)
+	entry, ok := tt.probe(key)
+	if !ok || entry.depth != 4 || entry.score != 50 || entry.bound != BOUND_LOWER || !sameMove(unpackMove(entry.move), move) {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if _, ok := tt.probe(other); ok {
+		t.Error("found a position never stored")
+	}
+
+	// a shallower search of another position doesn't evict a deeper one
+	tt.store(other, 2, 0, BOUND_EXACT, Move{})
+	if _, ok := tt.probe(key); !ok {
+		t.Error("deeper entry replaced by a shallower one")
+	}
+	// unless it is from an earlier search
+	tt.newSearch()
+	tt.store(other, 2, 0, BOUND_EXACT, Move{})
+	if _, ok := tt.probe(other); !ok {
+		t.Error("stale entry not replaced")
+	}
+	// the same position keeps its best move when stored again without one
+	tt.Clear()
+	tt.store(key, 1, 0, BOUND_UPPER, move)
+	tt.store(key, 1, 0, BOUND_UPPER, Move{})
+	if entry, _ := tt.probe(key); !sameMove(unpackMove(entry.move), move) {
+		t.Error("best move lost")
+	}
+
+	tt.Clear()
+	if _, ok := tt.probe(key); ok {
+		t.Error("entry left after Clear")
+	}
+}
+
+func TestPackMove(t *testing.T) {
+	for _, fen := range []string{START_FEN, "r3k2r/1P6/8/8/8/8/8/R3K2R w KQkq - 0 1"} {
+		var board Board
+		if err := board.LoadFEN(fen); err != nil {
+			t.Fatal(err)
+		}
+		for _, move := range board.GenerateMoves(board.turn) {
+			if got := unpackMove(packMove(move)); !sameMove(got, move) {
+				t.Errorf("%s unpacked as %s", move, got)
+			}
+		}
+	}
+	if packMove(Move{}) != 0 || unpackMove(0).init != nil {
+		t.Error("null move not packed as 0")
+	}
+}
@@ -0,0 +1,293 @@
+package chessEngine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// uciSession holds the state of one UCI conversation. The search runs in
+// its own goroutine so that "stop", "isready" and "quit" are answered while
+// it thinks.
+type uciSession struct {
+	engine    *NoobEngine
+	out       io.Writer
+	outMu     sync.Mutex
+	searching sync.WaitGroup
+	// closed by "stop", an infinite search waits for it before answering
+	stopped chan struct{}
+}
+
+// UCI speaks the Universal Chess Interface, reading commands from in and
+// writing replies to out until "quit" or the end of the input.
+func (ne *NoobEngine) UCI(in io.Reader, out io.Writer) error {
+	u := &uciSession{engine: ne, out: out}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "uci":
+			u.send("id name NoobEngine")
+			u.send("id author kishanshukla-2307")
+			for _, option := range uciOptions {
+				u.send("option name " + option.name + " " + option.definition)
+			}
+			u.send("uciok")
+		case "isready":
+			u.send("readyok")
+		case "ucinewgame":
+			u.stopSearch()
+			ne.board.InitializeBoard(false)
+			ne.tt.Clear()
+		case "position":
+			u.stopSearch()
+			if err := u.position(fields[1:]); err != nil {
+				u.send("info string " + err.Error())
+			}
+		case "go":
+			u.stopSearch()
+			u.goSearch(parseUCILimits(fields[1:]))
+		case "stop":
+			u.stopSearch()
+		case "setoption":
+			if err := u.setOption(fields[1:]); err != nil {
+				u.send("info string " + err.Error())
+			}
+		case "ponderhit", "debug", "register":
+		case "quit":
+			u.stopSearch()
+			return nil
+		default:
+			u.send("info string unknown command " + fields[0])
+		}
+	}
+	u.stopSearch()
+	return scanner.Err()
+}
+
+func (u *uciSession) send(line string) {
+	u.outMu.Lock()
+	defer u.outMu.Unlock()
+	fmt.Fprintln(u.out, line)
+}
+
+// stopSearch stops a running search and waits for its bestmove.
+func (u *uciSession) stopSearch() {
+	if u.stopped != nil {
+		close(u.stopped)
+		u.stopped = nil
+	}
+	u.engine.Stop()
+	u.searching.Wait()
+}
+
+// position handles "position [startpos | fen <fen>] [moves <move>...]".
+func (u *uciSession) position(args []string) error {
+	var board Board
+	movesAt := len(args)
+	for i, arg := range args {
+		if arg == "moves" {
+			movesAt = i
+			break
+		}
+	}
+	switch {
+	case len(args) > 0 && args[0] == "startpos":
+		board.InitializeBoard(false)
+	case len(args) > 0 && args[0] == "fen":
+		if err := board.LoadFEN(strings.Join(args[1:movesAt], " ")); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("position needs startpos or fen")
+	}
+	board.chess960 = board.chess960 || u.engine.chess960
+	for i := movesAt + 1; i < len(args); i++ {
+		move, err := ParseUCIMove(&board, args[i])
+		if err != nil {
+			return err
+		}
+		board.Push(move)
+	}
+	u.engine.board = board
+	return nil
+}
+
+func parseUCILimits(args []string) SearchLimits {
+	var limits SearchLimits
+	for i := 0; i < len(args); i++ {
+		if args[i] == "infinite" {
+			limits.Infinite = true
+			continue
+		}
+		if i+1 >= len(args) {
+			break
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil {
+			continue
+		}
+		ms := time.Duration(n) * time.Millisecond
+		switch args[i] {
+		case "depth":
+			limits.Depth = n
+		case "movetime":
+			limits.MoveTime = ms
+		case "wtime":
+			limits.WhiteTime = ms
+		case "btime":
+			limits.BlackTime = ms
+		case "winc":
+			limits.WhiteInc = ms
+		case "binc":
+			limits.BlackInc = ms
+		case "movestogo":
+			limits.MovesToGo = n
+		default:
+			continue
+		}
+		i++
+	}
+	return limits
+}
+
+func (u *uciSession) goSearch(limits SearchLimits) {
+	stopped := make(chan struct{})
+	u.stopped = stopped
+	u.engine.resetStop()
+	u.searching.Add(1)
+	go func() {
+		defer u.searching.Done()
+		side, notation := u.engine.board.turn, u.engine.board.MoveNotation
+		best := u.engine.Search(limits, func(info SearchInfo) {
+			u.send(uciInfo(info, side, notation))
+		})
+		if limits.Infinite {
+			<-stopped
+		}
+		if len(best.PV) == 0 {
+			u.send("bestmove 0000")
+			return
+		}
+		u.send("bestmove " + notation(best.PV[0]))
+	}()
+}
+
+func uciInfo(info SearchInfo, side bool, notation func(Move) string) string {
+	// UCI scores are from the point of view of the side to move
+	eval := info.Eval
+	if side {
+		eval = -eval
+	}
+	score := fmt.Sprintf("cp %d", eval)
+	if eval.IsMate() {
+		score = fmt.Sprintf("mate %d", eval.MateIn())
+	}
+	nps := uint64(0)
+	if ms := info.Time.Milliseconds(); ms > 0 {
+		nps = info.Nodes * 1000 / uint64(ms)
+	}
+	pv := make([]string, len(info.PV))
+	for i, move := range info.PV {
+		pv[i] = notation(move)
+	}
+	return fmt.Sprintf("info depth %d score %s nodes %d nps %d hashfull %d time %d pv %s",
+		info.Depth, score, info.Nodes, nps, info.HashFull, info.Time.Milliseconds(), strings.Join(pv, " "))
+}
+
+type uciOption struct {
+	name       string
+	definition string
+	set        func(ne *NoobEngine, value string) error
+}
+
+var uciOptions = []uciOption{
+	{"Depth", fmt.Sprintf("type spin default %d min 1 max %d", DEFAULT_DEPTH, MAX_DEPTH), func(ne *NoobEngine, value string) error {
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 1 || depth > MAX_DEPTH {
+			return fmt.Errorf("invalid Depth %q", value)
+		}
+		ne.depth = depth
+		return nil
+	}},
+	{"Hash", fmt.Sprintf("type spin default %d min 1 max %d", DEFAULT_HASH_MB, MAX_HASH_MB), func(ne *NoobEngine, value string) error {
+		mb, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid Hash %q", value)
+		}
+		return ne.SetHashSize(mb)
+	}},
+	{"Clear Hash", "type button", func(ne *NoobEngine, value string) error {
+		ne.tt.Clear()
+		return nil
+	}},
+	{"Quiescence", "type check default true", func(ne *NoobEngine, value string) error {
+		return setSearchOption(ne, value, func(options *SearchOptions, on bool) { options.Quiescence = on })
+	}},
+	{"Quiescence Checks", "type check default false", func(ne *NoobEngine, value string) error {
+		return setSearchOption(ne, value, func(options *SearchOptions, on bool) { options.QuiescenceChecks = on })
+	}},
+	{"Null Move", "type check default true", func(ne *NoobEngine, value string) error {
+		return setSearchOption(ne, value, func(options *SearchOptions, on bool) { options.NullMove = on })
+	}},
+	{"Late Move Reductions", "type check default true", func(ne *NoobEngine, value string) error {
+		return setSearchOption(ne, value, func(options *SearchOptions, on bool) { options.LateMoveReductions = on })
+	}},
+	{"Futility", "type check default true", func(ne *NoobEngine, value string) error {
+		return setSearchOption(ne, value, func(options *SearchOptions, on bool) { options.Futility = on })
+	}},
+	{"Reverse Futility", "type check default true", func(ne *NoobEngine, value string) error {
+		return setSearchOption(ne, value, func(options *SearchOptions, on bool) { options.ReverseFutility = on })
+	}},
+	{"UCI_Chess960", "type check default false", func(ne *NoobEngine, value string) error {
+		chess960, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid UCI_Chess960 %q", value)
+		}
+		ne.chess960 = chess960
+		ne.board.chess960 = chess960
+		return nil
+	}},
+}
+
+// setSearchOption sets one of the engine's search options from a check
+// option's value.
+func setSearchOption(ne *NoobEngine, value string, set func(options *SearchOptions, on bool)) error {
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid check value %q", value)
+	}
+	options := ne.SearchOptions()
+	set(&options, on)
+	ne.SetSearchOptions(options)
+	return nil
+}
+
+// setOption handles "setoption name <id> [value <x>]".
+func (u *uciSession) setOption(args []string) error {
+	var name, value []string
+	current := &name
+	for i, arg := range args {
+		switch {
+		case i == 0 && arg == "name":
+		case arg == "value" && current == &name:
+			current = &value
+		default:
+			*current = append(*current, arg)
+		}
+	}
+	for _, option := range uciOptions {
+		if strings.EqualFold(option.name, strings.Join(name, " ")) {
+			u.stopSearch()
+			return option.set(u.engine, strings.Join(value, " "))
+		}
+	}
+	return fmt.Errorf("unknown option %s", strings.Join(name, " "))
+}
@@ -0,0 +1,157 @@
+package chessEngine
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// session drives a protocol front end through pipes, line by line.
+type session struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+}
+
+// startSession runs front end, UCI or XBoard, of a new engine.
+func startSession(t *testing.T, frontEnd func(*NoobEngine, io.Reader, io.Writer) error) *session {
+	t.Helper()
+	ne, err := NewNoobEngine(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inReader, in := io.Pipe()
+	outReader, out := io.Pipe()
+	s := &session{t: t, in: in, lines: make(chan string, 1024), done: make(chan error, 1)}
+	go func() {
+		err := frontEnd(ne, inReader, out)
+		out.Close()
+		s.done <- err
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+		close(s.lines)
+	}()
+	return s
+}
+
+// run plays script: a line starting with "<" waits for an answer starting
+// with the rest of it, and returns the last one, any other line is sent.
+// Lines sent one after the other are written at once, so that the front end
+// reads them back to back as from a fast interface.
+func (s *session) run(script []string) string {
+	s.t.Helper()
+	var last string
+	var pending []string
+	for _, line := range script {
+		if prefix, ok := strings.CutPrefix(line, "<"); ok {
+			if len(pending) > 0 {
+				s.send(strings.Join(pending, "\n"))
+				pending = nil
+			}
+			last = s.expect(prefix)
+			continue
+		}
+		pending = append(pending, line)
+	}
+	if len(pending) > 0 {
+		s.send(strings.Join(pending, "\n"))
+	}
+	return last
+}
+
+// send writes lines, failing if the front end doesn't read them in time
+// because it is stuck on an earlier command.
+func (s *session) send(lines string) {
+	s.t.Helper()
+	sent := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(s.in, lines+"\n")
+		sent <- err
+	}()
+	select {
+	case err := <-sent:
+		if err != nil {
+			s.t.Fatalf("sending %q: %v", lines, err)
+		}
+	case <-time.After(5 * time.Second):
+		s.t.Fatalf("%q not read in time", lines)
+	}
+}
+
+func (s *session) expect(prefix string) string {
+	s.t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("session ended waiting for %q", prefix)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		case <-deadline:
+			s.t.Fatalf("no %q in time", prefix)
+		}
+	}
+}
+
+// close ends the input and waits for the front end to return.
+func (s *session) close() {
+	s.t.Helper()
+	s.in.Close()
+	go func() {
+		for range s.lines {
+		}
+	}()
+	select {
+	case err := <-s.done:
+		if err != nil {
+			s.t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		s.t.Fatal("session still running after the end of input")
+	}
+}
+
+func TestUCI(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []string
+		// position the last answer must be a legal bestmove in, if any
+		fen string
+	}{
+		{"handshake", []string{"uci", "<id name NoobEngine", "<option name Hash", "<uciok", "isready", "<readyok"}, ""},
+		{"unknown command", []string{"foo", "<info string unknown command foo"}, ""},
+		{"illegal move", []string{"position startpos moves e2e5", "<info string"}, ""},
+		{"go depth", []string{"position startpos", "go depth 2", "<info depth 2 ", "<bestmove "}, START_FEN},
+		{"position moves", []string{"position startpos moves e2e4 e7e5 g1f3", "go depth 2", "<bestmove "},
+			"rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"},
+		{"position fen", []string{"position fen 6k1/5ppp/8/8/8/8/4RPPP/4R1K1 w - - 0 1", "go depth 3",
+			"<info depth 3 score mate 1 ", "<bestmove e2e8"}, ""},
+		{"go infinite and stop", []string{"position startpos", "go infinite", "stop", "<bestmove "}, START_FEN},
+		{"stop while idle", []string{"stop", "isready", "<readyok", "go depth 1", "<bestmove "}, START_FEN},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := startSession(t, (*NoobEngine).UCI)
+			last := s.run(test.script)
+			s.close()
+			if test.fen == "" {
+				return
+			}
+			board := loadBoard(t, test.fen)
+			best := strings.TrimPrefix(last, "bestmove ")
+			if _, err := board.moveFromCoordinates(best); err != nil {
+				t.Errorf("bestmove %q: %v", best, err)
+			}
+		})
+	}
+}
@@ -9,14 +9,46 @@ func Abs[T int16 | int32 | int64 | int](x T) T {
 	return x
 }
 
+// ZOBRIST_SEED makes the keys, and so every hash, the same from one run to
+// the next.
+const ZOBRIST_SEED uint64 = 0x9E3779B97F4A7C15
+
+// The Zobrist keys a position's hash is the XOR of: one per piece on its
+// square, one when black is to move, one for the en passant file and one
+// per set of castling rights.
 var ZORBIST_TABLE [64][12]uint64
 var BLACK_TO__MOVE uint64
+var EN_PASSANT_FILE [8]uint64
+var CASTLING_RIGHTS [16]uint64
+
+func init() {
+	InitializeZobrist(ZOBRIST_SEED)
+}
 
-func initialize_zobrist() {
+// InitializeZobrist fills in the Zobrist keys from seed. Hashes computed
+// with other keys are meaningless afterwards.
+func InitializeZobrist(seed uint64) {
+	r := rand.New(rand.NewPCG(seed, seed^0xFFFFFFFFFFFFFFFF))
 	for i := 0; i < 64; i++ {
 		for j := 0; j < 12; j++ {
-			ZORBIST_TABLE[i][j] = rand.Uint64()
+			ZORBIST_TABLE[i][j] = r.Uint64()
+		}
+	}
+	BLACK_TO__MOVE = r.Uint64()
+	for i := 0; i < 8; i++ {
+		EN_PASSANT_FILE[i] = r.Uint64()
+	}
+	// one key per right, a set of rights being the XOR of its own
+	var rights [4]uint64
+	for i := range rights {
+		rights[i] = r.Uint64()
+	}
+	for set := range CASTLING_RIGHTS {
+		CASTLING_RIGHTS[set] = 0
+		for i, key := range rights {
+			if set&(1<<i) != 0 {
+				CASTLING_RIGHTS[set] ^= key
+			}
 		}
 	}
-	BLACK_TO__MOVE = rand.Uint64()
 }
@@ -0,0 +1,275 @@
+package chessEngine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// XBOARD_MATE is what "post" output adds mate scores to.
+const XBOARD_MATE = 100000
+
+// xboardSession holds the state of one CECP (XBoard/WinBoard) conversation.
+// Like the UCI session it thinks in its own goroutine and reuses Search.
+type xboardSession struct {
+	engine    *NoobEngine
+	out       io.Writer
+	outMu     sync.Mutex
+	searching sync.WaitGroup
+	// set when the running search has to be thrown away instead of played
+	discard *atomic.Bool
+
+	force bool
+	// the side the engine plays, true for black
+	side bool
+	// read by the search goroutine, so toggled atomically
+	post atomic.Bool
+
+	movesPerSession int
+	increment       time.Duration
+	moveTime        time.Duration
+	depth           int
+	ownTime         time.Duration
+	opponentTime    time.Duration
+}
+
+// XBoard speaks the Chess Engine Communication Protocol, reading commands
+// from in and writing replies to out until "quit" or the end of the input.
+func (ne *NoobEngine) XBoard(in io.Reader, out io.Writer) error {
+	x := &xboardSession{engine: ne, out: out, side: true}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		args := fields[1:]
+		switch fields[0] {
+		case "xboard", "accepted", "rejected", "random", "hard", "easy", "computer", "name", "rating", "ics", "white", "black":
+		case "protover":
+			x.send(`feature myname="NoobEngine" setboard=1 usermove=1 ping=1 playother=1 san=0 colors=0 memory=1 sigint=0 sigterm=0 analyze=0 done=1`)
+		case "new":
+			x.abortSearch()
+			ne.board.InitializeBoard(false)
+			ne.tt.Clear()
+			x.force, x.side = false, true
+			x.moveTime, x.depth = 0, 0
+		case "setboard":
+			x.abortSearch()
+			var board Board
+			if err := board.LoadFEN(strings.Join(args, " ")); err != nil {
+				x.send("tellusererror Illegal position: " + err.Error())
+				continue
+			}
+			ne.board = board
+		case "force":
+			x.abortSearch()
+			x.force = true
+		case "go":
+			x.abortSearch()
+			x.force = false
+			x.side = ne.board.turn
+			x.think()
+		case "playother":
+			x.abortSearch()
+			x.force = false
+			x.side = !ne.board.turn
+		case "usermove":
+			x.waitSearch()
+			x.userMove(args)
+		case "?":
+			ne.Stop()
+		case "undo":
+			x.abortSearch()
+			ne.board.Pop()
+		case "remove":
+			x.abortSearch()
+			ne.board.Pop()
+			ne.board.Pop()
+		case "level":
+			x.level(args)
+		case "st":
+			if len(args) > 0 {
+				seconds, _ := strconv.Atoi(args[0])
+				x.moveTime = time.Duration(seconds) * time.Second
+			}
+		case "sd":
+			if len(args) > 0 {
+				x.depth, _ = strconv.Atoi(args[0])
+			}
+		case "time", "otim":
+			if len(args) > 0 {
+				centiseconds, _ := strconv.Atoi(args[0])
+				if fields[0] == "time" {
+					x.ownTime = time.Duration(centiseconds) * 10 * time.Millisecond
+				} else {
+					x.opponentTime = time.Duration(centiseconds) * 10 * time.Millisecond
+				}
+			}
+		case "memory":
+			if len(args) > 0 {
+				x.abortSearch()
+				mb, _ := strconv.Atoi(args[0])
+				if err := ne.SetHashSize(mb); err != nil {
+					x.send("Error (" + err.Error() + "): memory")
+				}
+			}
+		case "post":
+			x.post.Store(true)
+		case "nopost":
+			x.post.Store(false)
+		case "ping":
+			x.waitSearch()
+			x.send("pong " + strings.Join(args, " "))
+		case "result":
+			x.abortSearch()
+			x.force = true
+		case "quit":
+			x.abortSearch()
+			return nil
+		default:
+			x.send("Error (unknown command): " + fields[0])
+		}
+	}
+	x.abortSearch()
+	return scanner.Err()
+}
+
+func (x *xboardSession) send(line string) {
+	x.outMu.Lock()
+	defer x.outMu.Unlock()
+	fmt.Fprintln(x.out, line)
+}
+
+// abortSearch stops a running search without playing its move.
+func (x *xboardSession) abortSearch() {
+	if x.discard != nil {
+		x.discard.Store(true)
+	}
+	x.engine.Stop()
+	x.searching.Wait()
+}
+
+func (x *xboardSession) waitSearch() {
+	x.searching.Wait()
+}
+
+func (x *xboardSession) userMove(args []string) {
+	if len(args) == 0 {
+		x.send("Error (no move): usermove")
+		return
+	}
+	move, err := ParseUCIMove(&x.engine.board, args[0])
+	if err != nil {
+		x.send("Illegal move: " + args[0])
+		return
+	}
+	x.engine.board.Push(move)
+	if x.gameOver() {
+		return
+	}
+	if !x.force && x.engine.board.turn == x.side {
+		x.think()
+	}
+}
+
+// level handles "level MPS BASE INC" where BASE is minutes or minutes:seconds.
+func (x *xboardSession) level(args []string) {
+	if len(args) < 3 {
+		x.send("Error (too few arguments): level")
+		return
+	}
+	x.movesPerSession, _ = strconv.Atoi(args[0])
+	minutes, seconds, _ := strings.Cut(args[1], ":")
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.Atoi(seconds)
+	x.ownTime = time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	x.opponentTime = x.ownTime
+	inc, _ := strconv.ParseFloat(args[2], 64)
+	x.increment = time.Duration(inc * float64(time.Second))
+	x.moveTime = 0
+}
+
+func (x *xboardSession) limits() SearchLimits {
+	limits := SearchLimits{Depth: x.depth, MoveTime: x.moveTime}
+	if x.moveTime > 0 || x.ownTime <= 0 {
+		return limits
+	}
+	white, black := x.ownTime, x.opponentTime
+	if x.side {
+		white, black = black, white
+	}
+	limits.WhiteTime, limits.BlackTime = white, black
+	limits.WhiteInc, limits.BlackInc = x.increment, x.increment
+	if x.movesPerSession > 0 {
+		limits.MovesToGo = x.movesPerSession - (x.engine.board.fullMoves-1)%x.movesPerSession
+	}
+	return limits
+}
+
+// think searches for the engine's move in a goroutine and plays it.
+func (x *xboardSession) think() {
+	discard := new(atomic.Bool)
+	x.discard = discard
+	limits := x.limits()
+	side := x.side
+	board := x.engine.board.Clone()
+	x.engine.resetStop()
+	x.searching.Add(1)
+	go func() {
+		defer x.searching.Done()
+		best := x.engine.Search(limits, func(info SearchInfo) {
+			if x.post.Load() {
+				x.send(xboardThinking(info, side, board))
+			}
+		})
+		if discard.Load() || len(best.PV) == 0 {
+			return
+		}
+		notation := x.engine.board.MoveNotation(best.PV[0])
+		x.engine.board.Push(best.PV[0])
+		x.send("move " + notation)
+		x.gameOver()
+	}()
+}
+
+// gameOver reports the result to the interface once the game has ended.
+func (x *xboardSession) gameOver() bool {
+	result, termination := x.engine.board.Status()
+	if result == ONGOING {
+		return false
+	}
+	comment := termination.String()
+	if termination == CHECKMATE && result == WHITE_WINS {
+		comment = "White mates"
+	} else if termination == CHECKMATE {
+		comment = "Black mates"
+	}
+	x.send(fmt.Sprintf("%s {%s}", result, comment))
+	x.force = true
+	return true
+}
+
+// xboardThinking formats a "post" line: ply, score in centipawns for the
+// side to move, time in centiseconds, nodes and the principal variation in
+// SAN from board. Mates in n moves score 100000 + n, or -100000 - n when
+// getting mated, as interfaces expect.
+func xboardThinking(info SearchInfo, side bool, board *Board) string {
+	eval := info.Eval
+	if side {
+		eval = -eval
+	}
+	cp := int(eval)
+	if mate := eval.MateIn(); mate > 0 {
+		cp = XBOARD_MATE + mate
+	} else if mate < 0 {
+		cp = -XBOARD_MATE + mate
+	}
+	pv := board.SANLine(info.PV)
+	return fmt.Sprintf("%d %d %d %d %s", info.Depth, cp, info.Time.Milliseconds()/10, info.Nodes, strings.Join(pv, " "))
+}
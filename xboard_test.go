@@ -0,0 +1,41 @@
+package chessEngine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestXBoard(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []string
+		// position the last answer must be a legal move in, if any
+		fen string
+	}{
+		{"protover", []string{"xboard", "protover 2", "<feature ", "ping 1", "<pong 1"}, ""},
+		{"usermove", []string{"new", "sd 2", "usermove e2e4", "<move "},
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"},
+		{"illegal move", []string{"new", "usermove e2e5", "<Illegal move: e2e5"}, ""},
+		{"setboard", []string{"force", "setboard 6k1/5ppp/8/8/8/8/4RPPP/4R1K1 w - - 0 1", "sd 3", "post", "go",
+			"<3 100001 ", "<move e2e8"}, ""},
+		{"bad setboard", []string{"setboard 8/8/8/8 w - - 0 1", "<tellusererror Illegal position"}, ""},
+		{"ping while thinking", []string{"new", "sd 3", "go", "ping 7", "<move ", "<pong 7"}, ""},
+		{"new while thinking", []string{"new", "level 0 10 0", "go", "new", "ping 2", "<pong 2"}, ""},
+		{"force while thinking", []string{"new", "level 0 10 0", "go", "force", "ping 3", "<pong 3"}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := startSession(t, (*NoobEngine).XBoard)
+			last := s.run(test.script)
+			s.close()
+			if test.fen == "" {
+				return
+			}
+			board := loadBoard(t, test.fen)
+			move := strings.TrimPrefix(last, "move ")
+			if _, err := board.moveFromCoordinates(move); err != nil {
+				t.Errorf("move %q: %v", move, err)
+			}
+		})
+	}
+}